@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"excel-seeder/database"
+	"excel-seeder/excel"
+	"excel-seeder/models"
+)
+
+// Warna ANSI untuk tabel diff di terminal. Semua kode warna sama panjang agar lebar kolom
+// tabwriter tetap rata.
+const (
+	colorReset   = "\033[0m"
+	colorDefault = "\033[39m"
+	colorGreen   = "\033[32m"
+	colorYellow  = "\033[33m"
+	colorRed     = "\033[31m"
+)
+
+// diffColors warna setiap status diff
+var diffColors = map[string]string{
+	excel.DiffNew:     colorGreen,
+	excel.DiffChanged: colorYellow,
+	excel.DiffAbsent:  colorRed,
+}
+
+// runDiff menjalankan perintah diff: membandingkan workbook dengan isi m_item tanpa menulis
+// apa pun, lalu menampilkan baris baru, baris yang berubah beserta field-nya, dan baris di
+// m_item yang tidak ada di workbook
+func runDiff(args []string) {
+	flags := newFlagSet("diff", "Compare a workbook with the current contents of m_item without writing anything. Shows new\nrows, existing rows that would change (per field) and m_item rows that are absent from the sheet.")
+	var (
+		excelPath  = flags.String("excel", "file/MasterBarang.xlsx", "Path to the input workbook: .xlsx, .csv or .tsv")
+		key        = flags.String("key", "barcode", "Natural key used to match workbook rows with m_item: 'barcode' or 'code'")
+		reportPath = flags.String("report", "", "Write the full diff to this path (.csv or .xlsx)")
+		limit      = flags.Int("limit", 50, "Maximum rows per status shown in the terminal table; 0 shows all")
+		activeOnly = flags.Bool("active-only", false, "Only compare against m_item rows with is_active = true")
+		noColor    = flags.Bool("no-color", false, "Disable colors in the terminal table")
+	)
+	var layout layoutFlags
+	layout.register(flags)
+	parseFlags(flags, args)
+	if *key != "barcode" && *key != "code" {
+		usageFailf("Invalid -key '%s', use 'barcode' or 'code'", *key)
+	}
+
+	ctx := signalContext()
+	cfg, profile := loadProfile("item")
+	layout.apply(&profile)
+
+	log.Printf("Parsing Excel file: %s", *excelPath)
+	items, validation, err := excel.ParseExcelToMItems(ctx, *excelPath, profile)
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, "item")
+		log.Fatalf("Failed to parse Excel file: %v", err)
+	}
+	if validation.RowsRejected > 0 {
+		log.Printf("Warning: %d rows rejected by validation are not part of the diff; their items may be listed as absent", validation.RowsRejected)
+	}
+
+	log.Printf("Connecting to database...")
+	db, err := database.ConnectDB(ctx, cfg)
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, "item")
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	current, err := models.LoadMItems(ctx, db, models.LoadFilter{ActiveOnly: *activeOnly})
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, "item")
+		log.Fatalf("Failed to load m_item: %v", err)
+	}
+	suppliers, err := models.LoadSupplierLookup(ctx, db)
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, "item")
+		log.Fatalf("Failed to load m_supp: %v", err)
+	}
+
+	result, err := excel.DiffMItems(items, current, validation, excel.DiffOptions{Key: *key, Suppliers: suppliers})
+	if err != nil {
+		log.Fatalf("Diff failed: %v", err)
+	}
+
+	color := !*noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	printDiffTable(result, *limit, color)
+	log.Printf("Diff against m_item by %s: %d new, %d changed, %d unchanged, %d absent from workbook",
+		result.Key, result.New, result.Changed, result.Unchanged, result.Absent)
+
+	if *reportPath != "" {
+		if err := excel.WriteDiffReport(result, *reportPath); err != nil {
+			log.Fatalf("Failed to write diff report: %v", err)
+		}
+		log.Printf("Diff report written to: %s", *reportPath)
+	}
+}
+
+// printDiffTable menulis hasil diff ke stdout sebagai tabel, dikelompokkan per status.
+// Setiap status dibatasi limit baris; sisanya hanya disebutkan jumlahnya.
+func printDiffTable(result *excel.DiffResult, limit int, color bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "STATUS\tKEY\tROW\tID\tNAME\tCHANGES"
+	if color {
+		header = colorDefault + header
+	}
+	fmt.Fprintln(w, header)
+
+	for _, status := range []string{excel.DiffNew, excel.DiffChanged, excel.DiffAbsent} {
+		shown, hidden := 0, 0
+		for _, row := range result.Rows {
+			if row.Status != status {
+				continue
+			}
+			if limit > 0 && shown >= limit {
+				hidden++
+				continue
+			}
+			shown++
+
+			changes := make([]string, len(row.Changes))
+			for i, change := range row.Changes {
+				changes[i] = fmt.Sprintf("%s %s → %s", change.Column, displayValue(change.Old), displayValue(change.New))
+			}
+			line := strings.Join([]string{status, row.Key, optionalNumber(int64(row.Row)), optionalNumber(row.ID), row.Name, strings.Join(changes, "; ")}, "\t")
+			if color {
+				line = diffColors[status] + line + colorReset
+			}
+			fmt.Fprintln(w, line)
+		}
+		if hidden > 0 {
+			line := fmt.Sprintf("%s\t... %d more\t\t\t\t", status, hidden)
+			if color {
+				line = diffColors[status] + line + colorReset
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	w.Flush()
+}
+
+// displayValue menampilkan nilai kosong sebagai (kosong) agar perubahan dari/ke NULL terlihat
+func displayValue(value string) string {
+	if value == "" {
+		return "(kosong)"
+	}
+	return value
+}
+
+// optionalNumber menampilkan angka, atau teks kosong untuk 0
+func optionalNumber(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// isTerminal memeriksa apakah file adalah terminal, untuk menentukan output berwarna
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"excel-seeder/database"
+	"excel-seeder/excel"
+	"excel-seeder/models"
+)
+
+// runExport menjalankan perintah export: membaca m_item atau m_supp dari database lalu
+// menulisnya ke workbook dengan header dari mapping profile
+func runExport(args []string) {
+	flags := newFlagSet("export", "Export m_item or m_supp from the database to a workbook using the headers of the mapping\nprofile, so the file can be edited and imported again with the same profile.")
+	var (
+		entity     = flags.String("entity", "item", "Entity to export: 'item' (m_item) or 'supplier' (m_supp)")
+		outputPath = flags.String("output", "", "Path of the .xlsx file to write; empty uses export/m_item.xlsx or export/m_supp.xlsx")
+		activeOnly = flags.Bool("active-only", false, "Only export rows with is_active = true")
+	)
+	parseFlags(flags, args)
+
+	ctx := signalContext()
+	cfg, profile := loadProfile(*entity)
+
+	if *outputPath == "" {
+		table := "m_item"
+		if *entity == "supplier" {
+			table = "m_supp"
+		}
+		*outputPath = filepath.Join("export", table+".xlsx")
+	}
+	if err := os.MkdirAll(filepath.Dir(*outputPath), 0755); err != nil {
+		log.Fatalf("Failed to create export directory: %v", err)
+	}
+
+	log.Printf("Connecting to database...")
+	db, err := database.ConnectDB(ctx, cfg)
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, *entity)
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	filter := models.LoadFilter{ActiveOnly: *activeOnly}
+	count := 0
+	switch *entity {
+	case "item":
+		var items []models.MItem
+		items, err = models.LoadMItems(ctx, db, filter)
+		if err == nil {
+			count = len(items)
+			err = excel.ExportMItems(items, profile, *outputPath)
+		}
+	case "supplier":
+		var supps []models.MSupp
+		supps, err = models.LoadMSupps(ctx, db, filter)
+		if err == nil {
+			count = len(supps)
+			err = excel.ExportMSupps(supps, profile, *outputPath)
+		}
+	}
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, *entity)
+		log.Fatalf("Export failed: %v", err)
+	}
+	log.Printf("Exported %d %ss to %s", count, *entity, *outputPath)
+}
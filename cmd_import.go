@@ -0,0 +1,661 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"excel-seeder/config"
+	"excel-seeder/database"
+	"excel-seeder/excel"
+	"excel-seeder/models"
+	"excel-seeder/utils"
+)
+
+const (
+	outputDatabase = "database" // Perintah import: tulis langsung ke database
+	outputSeeder   = "seeder"   // Perintah seed-sql: tulis file SQL seeder
+)
+
+// importJob opsi satu import workbook, diisi dari flag perintah import atau seed-sql
+type importJob struct {
+	excelPath  string
+	entity     string
+	output     string // outputDatabase atau outputSeeder
+	seederPath string
+	reportPath string
+	errorFile  string
+	opts       models.InsertOptions
+	dryRun     bool
+	stream     bool
+	strict     bool
+	layout     layoutFlags // Menimpa letak data (header, sheet) dari profile jika diisi
+	checkpoint string
+	resume     bool
+	history    bool
+	operator   string
+	sync       bool    // Nonaktifkan item yang tidak ada di workbook setelah import
+	syncLimit  float64 // Persentase maksimal item yang boleh dinonaktifkan -sync
+}
+
+// runImport menjalankan perintah import: parse workbook lalu tulis ke database
+func runImport(args []string) {
+	flags := newFlagSet("import", "Parse a workbook, validate it and write the rows to the database.")
+	job := importJob{output: outputDatabase}
+	flags.StringVar(&job.excelPath, "excel", "file/MasterBarang.xlsx", "Path to the input workbook: .xlsx, .csv or .tsv")
+	flags.StringVar(&job.entity, "entity", "item", "Entity to import: 'item' (m_item) or 'supplier' (m_supp)")
+	job.layout.register(flags)
+	flags.StringVar(&job.opts.Mode, "mode", models.ModeInsert, "Write mode: 'insert' for plain INSERT, 'upsert' to update existing rows by -upsert-key")
+	flags.StringVar(&job.opts.UpsertKey, "upsert-key", "", "Natural key used to match existing rows in upsert mode (item: 'barcode' or 'code', supplier: 'code', 'name' or 'npwp'); empty uses barcode for items and code for suppliers")
+	flags.StringVar(&job.reportPath, "report", "", "Write the validation report to this path (.csv, .json or .xlsx)")
+	flags.StringVar(&job.errorFile, "error-file", "", "Write rejected rows to this .xlsx file with an Error column and highlighted cells")
+	flags.StringVar(&job.opts.Loader, "loader", models.LoaderInsert, "Database loader: 'insert' for multi-value INSERT batches, 'copy' for COPY FROM STDIN in a single transaction, 'staging' to validate in a staging table with SQL before merging")
+	flags.IntVar(&job.opts.Workers, "workers", 1, "Number of batches inserted in parallel, each in its own transaction (capped by max_open_conn; not with -mode=upsert)")
+	flags.BoolVar(&job.opts.Atomic, "atomic", false, "Insert all batches in a single transaction so the import is all-or-nothing")
+	flags.BoolVar(&job.dryRun, "dry-run", false, "Run every batch against the database inside a transaction, report failing rows, then roll back")
+	flags.BoolVar(&job.stream, "stream", false, "Read the workbook row by row and write each batch as soon as it is parsed, keeping memory flat for very large files")
+	flags.BoolVar(&job.strict, "strict", false, "Abort without writing any data if any row fails validation")
+	flags.StringVar(&job.checkpoint, "checkpoint", "", "Checkpoint file recording committed rows; empty uses <excel>.checkpoint.json")
+	flags.BoolVar(&job.resume, "resume", false, "Continue an interrupted import from its checkpoint, skipping rows that were already committed")
+	flags.BoolVar(&job.history, "history", false, "Record the import in the import_runs table (created if missing, requires CREATE rights or db/import_runs_migration.sql) so it can be rolled back; tracked loads also write every inserted row id")
+	flags.StringVar(&job.operator, "operator", "", "Operator name recorded in import_runs; empty uses the current OS user")
+	flags.StringVar(&job.opts.MissingSupplier, "missing-supplier", models.MissingSupplierReject, "Items whose supplier is not in m_supp: 'reject' skips the row, 'create' adds the supplier")
+	flags.BoolVar(&job.sync, "sync", false, "Treat the workbook as the full catalog: after importing, deactivate active m_item rows in the workbook's m_bu_id scope whose -upsert-key is absent from the workbook")
+	flags.Float64Var(&job.syncLimit, "sync-threshold", 10, "Abort -sync without writing anything if more than this percentage of the active items in scope would be deactivated; raise it to confirm a larger deactivation")
+	parseFlags(flags, args)
+
+	importWorkbook(job)
+}
+
+// importWorkbook menjalankan import satu workbook ke database (perintah import) atau ke
+// file SQL seeder (perintah seed-sql): parse, validasi, lalu tulis sesuai job.output
+func importWorkbook(job importJob) {
+	log.Printf("Starting Excel to PostgreSQL parser...")
+	log.Printf("Config: %s", global.config)
+	log.Printf("Excel: %s", job.excelPath)
+	log.Printf("Entity: %s", job.entity)
+	log.Printf("Write mode: %s", job.opts.Mode)
+	if job.output == outputDatabase {
+		log.Printf("Loader: %s", job.opts.Loader)
+	}
+	if job.opts.Workers > 1 {
+		log.Printf("Workers: %d", job.opts.Workers)
+	}
+	if job.opts.Atomic {
+		log.Printf("Atomic: all batches run in a single transaction")
+	}
+
+	insertOpts := job.opts
+	if err := insertOpts.Validate(); err != nil {
+		usageFailf("Invalid write options: %v", err)
+	}
+	if job.stream && (job.dryRun || job.strict || insertOpts.Atomic || (insertOpts.Loader != "" && insertOpts.Loader != models.LoaderInsert)) {
+		usageFailf("-stream cannot be combined with -dry-run, -strict, -atomic or -loader=%s", insertOpts.Loader)
+	}
+	if job.sync && (job.entity != "item" || job.output != outputDatabase || job.stream) {
+		usageFailf("-sync is only supported when importing items to the database without -stream")
+	}
+
+	ctx := signalContext()
+	cfg, profile := loadProfile(job.entity)
+	job.layout.apply(&profile)
+
+	// Koneksi database dan riwayat import
+	var (
+		db       *sql.DB
+		fileHash string
+		err      error
+	)
+	if job.output == outputDatabase {
+		log.Printf("Connecting to database...")
+		db, err = database.ConnectDB(ctx, cfg)
+		if err != nil {
+			exitIfInterrupted(ctx, err, 0, job.entity)
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+		log.Printf("Database connection established")
+
+		fileHash, err = utils.FileSHA256(job.excelPath)
+		if err != nil {
+			log.Fatalf("Failed to hash Excel file: %v", err)
+		}
+		if job.history && !job.dryRun {
+			run := &models.ImportRun{
+				FileName:   filepath.Base(job.excelPath),
+				FileSHA256: fileHash,
+				Entity:     job.entity,
+				Profile:    global.profile,
+				Mode:       job.opts.Mode,
+				Loader:     job.opts.Loader,
+				Operator:   operatorName(job.operator),
+			}
+			if run.Profile == "" {
+				run.Profile = "default"
+			}
+			if err := models.StartImportRun(ctx, db, run); err != nil {
+				exitIfInterrupted(ctx, err, 0, job.entity)
+				log.Fatalf("Failed to record import run: %v", err)
+			}
+			currentRun.run = run
+			insertOpts.RunID = run.ID
+			log.Printf("Import run id: %d", run.ID)
+		}
+	}
+
+	// Checkpoint agar import yang terhenti bisa dilanjutkan tanpa menduplikasi baris
+	var cp *utils.Checkpoint
+	if job.output == outputDatabase && !job.dryRun && !insertOpts.Atomic && (insertOpts.Loader == "" || insertOpts.Loader == models.LoaderInsert) {
+		path := job.checkpoint
+		if path == "" {
+			path = job.excelPath + ".checkpoint.json"
+		}
+		cp, err = openCheckpoint(path, fileHash, job.excelPath, job.entity, insertOpts.Mode, job.resume)
+		if err != nil {
+			fatalf("Checkpoint: %v", err)
+		}
+	} else if job.resume {
+		usageFailf("-resume requires the insert loader, without -atomic or -dry-run")
+	}
+
+	if job.stream {
+		log.Printf("Streaming Excel file: %s", job.excelPath)
+		validation, count, err := streamImport(ctx, db, profile, job.excelPath, job.entity, job.output, job.seederPath, insertOpts, cp)
+		if validation != nil {
+			writeValidationOutputs(validation, job.reportPath, job.errorFile, job.excelPath, profile)
+		}
+		if err != nil {
+			logCheckpointHint(cp)
+			exitIfInterrupted(ctx, err, count, job.entity)
+			fatalf("Streaming import failed after writing %d %ss: %v", count, job.entity, err)
+		}
+		log.Printf("Successfully wrote %d %ss", count, job.entity)
+		removeCheckpoint(cp)
+		finishRun(models.RunSuccess, "")
+		log.Printf("Process completed successfully!")
+		return
+	}
+
+	// Parse Excel file
+	log.Printf("Parsing Excel file: %s", job.excelPath)
+	var (
+		items      []models.MItem
+		supps      []models.MSupp
+		count      int
+		validation *excel.ValidationResult
+	)
+	switch job.entity {
+	case "item":
+		items, validation, err = excel.ParseExcelToMItems(ctx, job.excelPath, profile)
+		count = len(items)
+	case "supplier":
+		supps, validation, err = excel.ParseExcelToMSupps(ctx, job.excelPath, profile)
+		count = len(supps)
+	}
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, job.entity)
+		fatalf("Failed to parse Excel file: %v", err)
+	}
+	currentRun.validation = validation
+	log.Printf("Successfully parsed %d %ss from Excel", count, job.entity)
+
+	// Sync memakai seluruh isi workbook, termasuk baris yang sudah commit sebelum -resume
+	syncItems := items
+
+	if cp != nil && len(cp.Rows) > 0 {
+		switch job.entity {
+		case "item":
+			items = skipCommitted(items, cp, itemSourceRow)
+			count = len(items)
+		case "supplier":
+			supps = skipCommitted(supps, cp, suppSourceRow)
+			count = len(supps)
+		}
+	}
+
+	if job.output == outputDatabase && job.entity == "item" {
+		items, err = resolveItemSuppliers(ctx, db, items, insertOpts.MissingSupplier, validation)
+		if err != nil {
+			exitIfInterrupted(ctx, err, 0, job.entity)
+			fatalf("Failed to resolve item suppliers: %v", err)
+		}
+		count = len(items)
+	}
+
+	// Check column constraints (length, precision, NOT NULL) before anything is written
+	switch job.entity {
+	case "item":
+		items = rejectRowErrors(items, models.CheckMItemConstraints(items), validation, excel.RuleConstraint, itemSourceRow)
+		count = len(items)
+	case "supplier":
+		supps = rejectRowErrors(supps, models.CheckMSuppConstraints(supps), validation, excel.RuleConstraint, suppSourceRow)
+		count = len(supps)
+	}
+
+	// Staging loader: muat ke tabel staging, validasi dengan SQL dan tampilkan diff sebelum merge
+	var staging *models.Staging
+	if insertOpts.Loader == models.LoaderStaging && job.output == outputDatabase && count > 0 {
+		log.Printf("Loading %d %ss into staging table...", count, job.entity)
+		switch job.entity {
+		case "item":
+			staging, err = models.StageMItems(ctx, db, items, insertOpts)
+		case "supplier":
+			staging, err = models.StageMSupps(ctx, db, supps, insertOpts)
+		}
+		if err != nil {
+			exitIfInterrupted(ctx, err, 0, job.entity)
+			fatalf("Failed to load staging table: %v", err)
+		}
+		defer staging.Rollback()
+
+		rowErrors, err := staging.Validate()
+		if err != nil {
+			exitIfInterrupted(ctx, err, 0, job.entity)
+			fatalf("Staging validation failed: %v", err)
+		}
+		if err := staging.Reject(rowErrors); err != nil {
+			fatalf("Staging validation failed: %v", err)
+		}
+		switch job.entity {
+		case "item":
+			items = rejectRowErrors(items, rowErrors, validation, excel.RuleStaging, itemSourceRow)
+			count = len(items)
+		case "supplier":
+			supps = rejectRowErrors(supps, rowErrors, validation, excel.RuleStaging, suppSourceRow)
+			count = len(supps)
+		}
+
+		diff, err := staging.Diff()
+		if err != nil {
+			fatalf("Failed to compare staging table: %v", err)
+		}
+		logStagingDiff(diff, insertOpts.Mode)
+	}
+
+	if job.dryRun && count > 0 && staging == nil {
+		log.Printf("Dry run: executing all batches inside a transaction that will be rolled back...")
+		var rowErrors []models.RowError
+		switch job.entity {
+		case "item":
+			rowErrors, err = models.DryRunMItems(ctx, db, items, insertOpts)
+			if err == nil {
+				items = rejectRowErrors(items, rowErrors, validation, excel.RuleDatabase, itemSourceRow)
+				count = len(items)
+			}
+		case "supplier":
+			rowErrors, err = models.DryRunMSupps(ctx, db, supps, insertOpts)
+			if err == nil {
+				supps = rejectRowErrors(supps, rowErrors, validation, excel.RuleDatabase, suppSourceRow)
+				count = len(supps)
+			}
+		}
+		if err != nil {
+			exitIfInterrupted(ctx, err, 0, job.entity)
+			fatalf("Dry run failed: %v", err)
+		}
+		log.Printf("Dry run: %d rows rejected by the database", len(rowErrors))
+	}
+
+	// Validation summary, report and strict mode
+	writeValidationOutputs(validation, job.reportPath, job.errorFile, job.excelPath, profile)
+	if job.strict && validation.HasErrors() {
+		validationFailf("Strict mode: %d validation errors found, aborting without writing any data", validation.ErrorCount())
+	}
+
+	var syncPlan *models.SyncPlan
+	if job.sync {
+		syncPlan = planSync(ctx, db, syncItems, insertOpts, validation, job.syncLimit)
+	}
+
+	if job.dryRun {
+		log.Printf("Dry run completed: %d %ss would be written, %d rows rejected", count, job.entity, validation.RowsRejected)
+		if validation.HasErrors() {
+			os.Exit(exitValidation)
+		}
+		return
+	}
+
+	if count == 0 {
+		log.Printf("No %ss found in Excel file", job.entity)
+		applySync(ctx, db, syncPlan, insertOpts.RunID)
+		finishRun(models.RunSuccess, "")
+		return
+	}
+
+	// Handle output based on mode
+	switch job.output {
+	case outputDatabase:
+		// Direct database insertion
+		if staging != nil {
+			log.Printf("Merging %s into the live table...", staging.Name)
+			updated, inserted, err := staging.Merge()
+			if err != nil {
+				exitIfInterrupted(ctx, err, 0, job.entity)
+				fatalf("Failed to merge %ss: %v", job.entity, err)
+			}
+			log.Printf("Successfully merged %d %ss to database (%d updated, %d inserted)", count, job.entity, updated, inserted)
+			currentRun.committed = updated + inserted
+			applySync(ctx, db, syncPlan, insertOpts.RunID)
+			finishRun(models.RunSuccess, "")
+			break
+		}
+
+		log.Printf("Starting batch insert to database...")
+		switch job.entity {
+		case "item":
+			insertOpts.OnCommit = checkpointOnCommit(cp, sourceRows(items, itemSourceRow))
+			err = models.InsertMItems(ctx, db, items, insertOpts)
+		case "supplier":
+			insertOpts.OnCommit = checkpointOnCommit(cp, sourceRows(supps, suppSourceRow))
+			err = models.InsertMSupps(ctx, db, supps, insertOpts)
+		}
+		if err != nil {
+			logCheckpointHint(cp)
+			exitIfInterrupted(ctx, err, 0, job.entity)
+			fatalf("Failed to insert %ss: %v", job.entity, err)
+		}
+		log.Printf("Successfully inserted %d %ss to database", count, job.entity)
+		removeCheckpoint(cp)
+		currentRun.committed = count
+		applySync(ctx, db, syncPlan, insertOpts.RunID)
+		finishRun(models.RunSuccess, "")
+
+	case outputSeeder:
+		// Generate SQL seeder file
+		log.Printf("Generating SQL seeder file...")
+
+		seederDir := filepath.Dir(job.seederPath)
+		err := createDirIfNotExists(seederDir)
+		if err != nil {
+			fatalf("Failed to create seeder directory: %v", err)
+		}
+
+		switch job.entity {
+		case "item":
+			err = models.GenerateSeederSQL(items, job.seederPath, insertOpts)
+		case "supplier":
+			err = models.GenerateSupplierSeederSQL(supps, job.seederPath, insertOpts)
+		}
+		if err != nil {
+			fatalf("Failed to generate seeder file: %v", err)
+		}
+		log.Printf("Successfully generated seeder file: %s", job.seederPath)
+		log.Printf("You can run the seeder with: psql -d your_database -f %s", job.seederPath)
+	}
+
+	log.Printf("Process completed successfully!")
+}
+
+// streamImport membaca workbook baris demi baris dan langsung menulis setiap batch ke
+// database atau file seeder. Supplier dan constraint dicek per batch; baris yang ditolak
+// dicatat di hasil validasi. Mengembalikan jumlah baris yang sudah ditulis.
+func streamImport(ctx context.Context, db *sql.DB, profile config.MappingProfile, excelPath, entity, outputMode, seederPath string, opts models.InsertOptions, cp *utils.Checkpoint) (*excel.ValidationResult, int, error) {
+	var seeder *models.SeederWriter
+	var err error
+	if outputMode != outputDatabase {
+		if err := createDirIfNotExists(filepath.Dir(seederPath)); err != nil {
+			return nil, 0, fmt.Errorf("failed to create seeder directory: %v", err)
+		}
+		if entity == "item" {
+			seeder, err = models.NewMItemSeeder(seederPath, opts)
+		} else {
+			seeder, err = models.NewMSuppSeeder(seederPath, opts)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		defer func() {
+			if err := seeder.Close(); err != nil {
+				log.Printf("Warning: failed to close seeder file: %v", err)
+			}
+		}()
+	}
+
+	written := 0
+	var validation *excel.ValidationResult
+	switch entity {
+	case "item":
+		batchSize := models.PostgreSQLParamLimit / models.MItemColumnCount
+		validation, err = excel.StreamExcelToMItems(ctx, excelPath, profile, batchSize, func(items []models.MItem, validation *excel.ValidationResult) error {
+			currentRun.validation = validation
+			var err error
+			if cp != nil {
+				items = skipCommitted(items, cp, itemSourceRow)
+			}
+			if db != nil {
+				items, err = resolveItemSuppliers(ctx, db, items, opts.MissingSupplier, validation)
+				if err != nil {
+					return fmt.Errorf("failed to resolve item suppliers: %v", err)
+				}
+			}
+			items = rejectRowErrors(items, models.CheckMItemConstraints(items), validation, excel.RuleConstraint, itemSourceRow)
+			if len(items) == 0 {
+				return nil
+			}
+
+			if db != nil {
+				opts.OnCommit = checkpointOnCommit(cp, sourceRows(items, itemSourceRow))
+				err = models.InsertMItems(ctx, db, items, opts)
+			} else {
+				err = seeder.WriteMItems(items)
+			}
+			if err != nil {
+				return err
+			}
+			written += len(items)
+			log.Printf("Streamed %d items (up to row %d)", written, items[len(items)-1].SourceRow)
+			return nil
+		})
+	case "supplier":
+		batchSize := models.PostgreSQLParamLimit / models.MSuppColumnCount
+		validation, err = excel.StreamExcelToMSupps(ctx, excelPath, profile, batchSize, func(supps []models.MSupp, validation *excel.ValidationResult) error {
+			currentRun.validation = validation
+			if cp != nil {
+				supps = skipCommitted(supps, cp, suppSourceRow)
+			}
+			supps = rejectRowErrors(supps, models.CheckMSuppConstraints(supps), validation, excel.RuleConstraint, suppSourceRow)
+			if len(supps) == 0 {
+				return nil
+			}
+
+			var err error
+			if db != nil {
+				opts.OnCommit = checkpointOnCommit(cp, sourceRows(supps, suppSourceRow))
+				err = models.InsertMSupps(ctx, db, supps, opts)
+			} else {
+				err = seeder.WriteMSupps(supps)
+			}
+			if err != nil {
+				return err
+			}
+			written += len(supps)
+			log.Printf("Streamed %d suppliers (up to row %d)", written, supps[len(supps)-1].SourceRow)
+			return nil
+		})
+	}
+
+	return validation, written, err
+}
+
+// resolveItemSuppliers mengisi m_supp_id untuk item yang membawa nama supplier dari Excel.
+// Item yang ditolak dicatat ke hasil validasi. Belum ada yang ditulis ke database: supplier
+// baru (-missing-supplier=create) dibuat bersama item-nya setelah semua pengecekan lolos.
+func resolveItemSuppliers(ctx context.Context, db *sql.DB, items []models.MItem, missing string, validation *excel.ValidationResult) ([]models.MItem, error) {
+	hasSupplier := false
+	for _, item := range items {
+		if item.SupplierName != nil {
+			hasSupplier = true
+			break
+		}
+	}
+	if !hasSupplier {
+		return items, nil
+	}
+
+	log.Printf("Resolving item suppliers against m_supp...")
+	resolved, rejected, err := models.ResolveItemSuppliers(ctx, db, items, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, rejection := range rejected {
+		log.Printf("Row %d: %s, skipping", rejection.Item.SourceRow, rejection.Reason)
+		issue := validation.FieldIssue(rejection.Item.SourceSheet, rejection.Item.SourceRow, "SupplierName")
+		issue.Value = *rejection.Item.SupplierName
+		issue.Rule = excel.RuleSupplier
+		issue.Message = rejection.Reason
+		issue.Severity = excel.SeverityError
+		validation.Reject(issue)
+	}
+	log.Printf("Resolved suppliers for %d items, %d rejected", len(resolved), len(rejected))
+
+	return resolved, nil
+}
+
+// logStagingDiff menampilkan ringkasan perubahan yang akan dilakukan merge dari staging
+func logStagingDiff(diff models.StagingDiff, mode string) {
+	if mode == models.ModeUpsert {
+		log.Printf("Staging diff: %d rows, %d new rows will be inserted, %d existing rows will be updated", diff.Rows, diff.New, diff.Existing)
+	} else {
+		log.Printf("Staging diff: %d rows will be inserted, %d of them already exist in the live table", diff.Rows, diff.Existing)
+	}
+	if len(diff.ExistingKeys) > 0 {
+		log.Printf("Staging diff: existing keys include %s", strings.Join(diff.ExistingKeys, ", "))
+	}
+}
+
+// planSync menghitung item yang akan dinonaktifkan -sync sebelum apa pun ditulis. Sync
+// dibatalkan jika ada baris yang ditolak (item-nya akan ikut dinonaktifkan) atau jika
+// persentase item yang dinonaktifkan melebihi threshold.
+func planSync(ctx context.Context, db *sql.DB, items []models.MItem, opts models.InsertOptions, validation *excel.ValidationResult, threshold float64) *models.SyncPlan {
+	if validation.RowsRejected > 0 {
+		validationFailf("Sync: %d rows were rejected and their items would be deactivated; fix them before running -sync", validation.RowsRejected)
+	}
+
+	plan, err := models.PlanMItemSync(ctx, db, items, opts)
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, "item")
+		fatalf("Failed to plan sync: %v", err)
+	}
+	log.Printf("Sync: %d of %d active items in %s are absent from the workbook and will be deactivated (%.1f%%)",
+		plan.Missing, plan.Active, plan.Scope(), plan.Percent())
+	if plan.Percent() > threshold {
+		validationFailf("Sync: %.1f%% of active items would be deactivated, above -sync-threshold=%g; re-run with a higher -sync-threshold to confirm",
+			plan.Percent(), threshold)
+	}
+	return plan
+}
+
+// applySync menonaktifkan item dalam plan setelah import berhasil
+func applySync(ctx context.Context, db *sql.DB, plan *models.SyncPlan, runID int64) {
+	if plan == nil {
+		return
+	}
+	deactivated, err := models.DeactivateMItems(ctx, db, plan, runID)
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, "item")
+		fatalf("Sync failed after importing: %v", err)
+	}
+	log.Printf("Sync: deactivated %d items absent from the workbook", deactivated)
+}
+
+// openCheckpoint menyiapkan checkpoint untuk import ini. Checkpoint dari run sebelumnya untuk
+// workbook yang sama hanya dipakai dengan -resume; tanpa -resume import ditolak agar baris
+// yang sudah commit tidak terduplikasi.
+func openCheckpoint(path, hash, excelPath, entity, mode string, resume bool) (*utils.Checkpoint, error) {
+	if mode == "" {
+		mode = models.ModeInsert
+	}
+
+	existing, err := utils.LoadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case existing != nil && (existing.SHA256 != hash || existing.Entity != entity || existing.Mode != mode):
+		if resume {
+			return nil, fmt.Errorf("checkpoint %s was written for a different workbook, entity or mode; delete it to start over", path)
+		}
+		log.Printf("Replacing checkpoint %s written for a different workbook, entity or mode", path)
+	case existing != nil && !resume:
+		return nil, fmt.Errorf("a previous import of this workbook stopped after %d rows (checkpoint %s); rerun with -resume to continue, or delete the checkpoint to start over", existing.Committed, path)
+	case existing != nil:
+		log.Printf("Resuming from checkpoint %s: %d rows already committed", path, existing.Committed)
+		return existing, nil
+	case resume:
+		return nil, fmt.Errorf("no checkpoint found at %s", path)
+	}
+
+	cp := utils.NewCheckpoint(path)
+	cp.File = excelPath
+	cp.SHA256 = hash
+	cp.Entity = entity
+	cp.Mode = mode
+	return cp, nil
+}
+
+// checkpointOnCommit membuat callback InsertOptions.OnCommit yang menghitung baris yang
+// sudah commit untuk import_runs dan mencatat baris Excel-nya ke checkpoint (jika ada).
+// rows berisi posisi tiap record di workbook; batch yang melewati batas sheet dicatat per sheet.
+func checkpointOnCommit(cp *utils.Checkpoint, rows []rowRef) func(start, end int) {
+	return func(start, end int) {
+		currentRun.committed += end - start
+		if cp == nil {
+			return
+		}
+		for first := start; first < end; {
+			last := first
+			for last+1 < end && rows[last+1].sheet == rows[first].sheet {
+				last++
+			}
+			cp.Add(rows[first].sheet, rows[first].row, rows[last].row, last-first+1)
+			first = last + 1
+		}
+		if err := cp.Save(); err != nil {
+			log.Printf("Warning: failed to save checkpoint: %v", err)
+		}
+	}
+}
+
+// logCheckpointHint memberi tahu cara melanjutkan import yang gagal setelah sebagian commit
+func logCheckpointHint(cp *utils.Checkpoint) {
+	if cp != nil && cp.Committed > 0 {
+		log.Printf("Checkpoint saved to %s (%d rows committed), rerun with -resume to continue", cp.Path(), cp.Committed)
+	}
+}
+
+// removeCheckpoint menghapus checkpoint setelah import selesai
+func removeCheckpoint(cp *utils.Checkpoint) {
+	if cp == nil {
+		return
+	}
+	if err := cp.Remove(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// skipCommitted membuang record yang sudah commit pada run sebelumnya menurut checkpoint
+func skipCommitted[T any](records []T, cp *utils.Checkpoint, sourceRow func(T) rowRef) []T {
+	kept := make([]T, 0, len(records))
+	for _, record := range records {
+		if ref := sourceRow(record); !cp.Done(ref.sheet, ref.row) {
+			kept = append(kept, record)
+		}
+	}
+	if skipped := len(records) - len(kept); skipped > 0 {
+		log.Printf("Resume: skipping %d rows committed by a previous run", skipped)
+	}
+	return kept
+}
+
+// sourceRows mengumpulkan posisi setiap record di workbook
+func sourceRows[T any](records []T, sourceRow func(T) rowRef) []rowRef {
+	rows := make([]rowRef, len(records))
+	for i, record := range records {
+		rows[i] = sourceRow(record)
+	}
+	return rows
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+
+	"excel-seeder/config"
+	"excel-seeder/database"
+	"excel-seeder/models"
+)
+
+// runRollback menjalankan perintah rollback -run=<id>: menghapus baris yang
+// di-insert oleh import run tersebut dan mengembalikan baris yang di-update-nya
+func runRollback(args []string) {
+	flags := newFlagSet("rollback", "Undo a previous import run: delete the rows it inserted and restore the rows it updated\nfrom the before-images recorded in import_run_rows.")
+	var (
+		runID  = flags.Int64("run", 0, "Id of the import run to roll back (see the import_runs table)")
+		dryRun = flags.Bool("dry-run", false, "Show how many rows would be deleted and restored, then roll back")
+		force  = flags.Bool("force", false, "Roll back even if the run is still marked running or its rows were changed by later runs")
+	)
+	parseFlags(flags, args)
+
+	if *runID <= 0 {
+		usageFailf("rollback requires -run=<id>")
+	}
+
+	ctx := signalContext()
+
+	cfg, err := config.LoadConfig(global.config)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	log.Printf("Connecting to database...")
+	db, err := database.ConnectDB(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if *dryRun {
+		log.Printf("Dry run: rollback of import run %d will not be committed", *runID)
+	}
+	result, err := models.RollbackImportRun(ctx, db, *runID, models.RollbackOptions{DryRun: *dryRun, Force: *force})
+	if err != nil {
+		log.Fatalf("Rollback failed: %v", err)
+	}
+
+	run := result.Run
+	log.Printf("Import run %d: %s (%s, %s mode) started %s, outcome %s",
+		run.ID, run.FileName, run.Entity, run.Mode, run.StartedAt.Format("2006-01-02 15:04:05"), run.Outcome)
+	deleted, restored := 0, 0
+	for name, count := range result.Deleted {
+		deleted += count
+		restored += result.Restored[name]
+	}
+	if *dryRun {
+		log.Printf("Dry run: %d rows would be deleted and %d rows restored", deleted, restored)
+		return
+	}
+	log.Printf("Rolled back import run %d: %d rows deleted, %d rows restored", run.ID, deleted, restored)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "excel-seeder/models"
+
+// runSeedSQL menjalankan perintah seed-sql: parse workbook lalu tulis file SQL seeder
+func runSeedSQL(args []string) {
+	flags := newFlagSet("seed-sql", "Parse a workbook, validate it and write the rows to a SQL seeder file that can be run with psql.")
+	job := importJob{output: outputSeeder}
+	flags.StringVar(&job.excelPath, "excel", "file/MasterBarang.xlsx", "Path to the input workbook: .xlsx, .csv or .tsv")
+	flags.StringVar(&job.entity, "entity", "item", "Entity to import: 'item' (m_item) or 'supplier' (m_supp)")
+	job.layout.register(flags)
+	flags.StringVar(&job.seederPath, "seeder-path", "seeder/seeder.sql", "Path for the generated seeder file")
+	flags.StringVar(&job.opts.Mode, "mode", models.ModeInsert, "Write mode: 'insert' for plain INSERT, 'upsert' to update existing rows by -upsert-key")
+	flags.StringVar(&job.opts.UpsertKey, "upsert-key", "", "Natural key used to match existing rows in upsert mode (item: 'barcode' or 'code', supplier: 'code', 'name' or 'npwp'); empty uses barcode for items and code for suppliers")
+	flags.StringVar(&job.reportPath, "report", "", "Write the validation report to this path (.csv, .json or .xlsx)")
+	flags.StringVar(&job.errorFile, "error-file", "", "Write rejected rows to this .xlsx file with an Error column and highlighted cells")
+	flags.BoolVar(&job.stream, "stream", false, "Read the workbook row by row and write each batch as soon as it is parsed, keeping memory flat for very large files")
+	flags.BoolVar(&job.strict, "strict", false, "Abort without writing any file if any row fails validation")
+	flags.StringVar(&job.opts.MissingSupplier, "missing-supplier", models.MissingSupplierReject, "Items whose supplier is not in m_supp when the seeder runs: 'reject' aborts the seeder, 'create' adds the supplier")
+	parseFlags(flags, args)
+
+	importWorkbook(job)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"excel-seeder/excel"
+)
+
+// runTemplate menjalankan perintah template: menulis workbook kosong dengan header dari
+// mapping profile untuk diisi vendor
+func runTemplate(args []string) {
+	flags := newFlagSet("template", "Write a blank import workbook with the exact headers of the mapping profile, a notes row\nmarking required fields, an example row, dropdowns for yes/no and unit columns and number\nformats for price and quantity columns.\n\nThe example row below the header is imported like any other row: delete it before importing.\nThe notes row puts the header on row 2, so import the filled template with -header-row=2\n(or the row printed after the template is written).")
+	var (
+		entity     = flags.String("entity", "item", "Entity of the template: 'item' (m_item) or 'supplier' (m_supp)")
+		outputPath = flags.String("output", "", "Path of the .xlsx file to write; empty uses template/template_item.xlsx or template/template_supplier.xlsx")
+		units      = flags.String("units", strings.Join(excel.DefaultTemplateUnits, ","), "Comma separated choices for the unit dropdown")
+	)
+	parseFlags(flags, args)
+
+	_, profile := loadProfile(*entity)
+
+	if *outputPath == "" {
+		*outputPath = filepath.Join("template", "template_"+*entity+".xlsx")
+	}
+	if err := os.MkdirAll(filepath.Dir(*outputPath), 0755); err != nil {
+		log.Fatalf("Failed to create template directory: %v", err)
+	}
+
+	opts := excel.TemplateOptions{}
+	for _, unit := range strings.Split(*units, ",") {
+		if unit = strings.TrimSpace(unit); unit != "" {
+			opts.Units = append(opts.Units, unit)
+		}
+	}
+
+	var err error
+	switch *entity {
+	case "item":
+		err = excel.WriteMItemTemplate(profile, *outputPath, opts)
+	case "supplier":
+		err = excel.WriteMSuppTemplate(profile, *outputPath, opts)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write template: %v", err)
+	}
+
+	headerRow, _ := excel.TemplateHeaderRow(profile)
+	log.Printf("Template written: %s (%d columns)", *outputPath, len(profile.Fields))
+	if headerRow != profile.HeaderRow {
+		log.Printf("The template has a notes row above the header; delete the example row and import it with -header-row=%d", headerRow)
+	} else {
+		log.Printf("Delete the example row before importing the filled template")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log"
+
+	"excel-seeder/excel"
+	"excel-seeder/models"
+)
+
+// runValidate menjalankan perintah validate: parse workbook dan cek setiap baris terhadap
+// mapping profile dan constraint kolom tabel tujuan, tanpa menulis apa pun ke database
+func runValidate(args []string) {
+	flags := newFlagSet("validate", "Parse a workbook and check every row against the mapping profile and the column constraints\nof the target table without touching the database. Exits with code 3 if any row is rejected.")
+	var (
+		excelPath  = flags.String("excel", "file/MasterBarang.xlsx", "Path to the input workbook: .xlsx, .csv or .tsv")
+		entity     = flags.String("entity", "item", "Entity to validate: 'item' (m_item) or 'supplier' (m_supp)")
+		reportPath = flags.String("report", "", "Write the validation report to this path (.csv, .json or .xlsx)")
+		errorFile  = flags.String("error-file", "", "Write rejected rows to this .xlsx file with an Error column and highlighted cells")
+	)
+	var layout layoutFlags
+	layout.register(flags)
+	parseFlags(flags, args)
+
+	ctx := signalContext()
+	_, profile := loadProfile(*entity)
+	layout.apply(&profile)
+
+	log.Printf("Validating Excel file: %s", *excelPath)
+	var (
+		validation *excel.ValidationResult
+		count      int
+		err        error
+	)
+	switch *entity {
+	case "item":
+		var items []models.MItem
+		items, validation, err = excel.ParseExcelToMItems(ctx, *excelPath, profile)
+		if err == nil {
+			items = rejectRowErrors(items, models.CheckMItemConstraints(items), validation, excel.RuleConstraint, itemSourceRow)
+			count = len(items)
+		}
+	case "supplier":
+		var supps []models.MSupp
+		supps, validation, err = excel.ParseExcelToMSupps(ctx, *excelPath, profile)
+		if err == nil {
+			supps = rejectRowErrors(supps, models.CheckMSuppConstraints(supps), validation, excel.RuleConstraint, suppSourceRow)
+			count = len(supps)
+		}
+	}
+	if err != nil {
+		exitIfInterrupted(ctx, err, 0, *entity)
+		log.Fatalf("Failed to parse Excel file: %v", err)
+	}
+
+	writeValidationOutputs(validation, *reportPath, *errorFile, *excelPath, profile)
+	if validation.HasErrors() {
+		validationFailf("Validation failed: %d of %d rows rejected", validation.RowsRejected, validation.RowsRead)
+	}
+	log.Printf("Validation passed: %d %ss are valid", count, *entity)
+}
@@ -8,9 +8,10 @@ import (
 )
 
 type Config struct {
-	Env      string         `yaml:"env"`
-	Log      LogConfig      `yaml:"log"`
-	Database DatabaseConfig `yaml:"database"`
+	Env      string                    `yaml:"env"`
+	Log      LogConfig                 `yaml:"log"`
+	Database DatabaseConfig            `yaml:"database"`
+	Profiles map[string]MappingProfile `yaml:"profiles"`
 }
 
 type LogConfig struct {
@@ -30,6 +31,84 @@ type DatabaseConfig struct {
 	ConnMaxLifetime string `yaml:"conn_max_lifetime"`
 }
 
+// MappingProfile mapping kolom Excel ke field struct untuk satu format spreadsheet
+type MappingProfile struct {
+	Entity    string         `yaml:"entity"`     // item atau supplier
+	Sheet     string         `yaml:"sheet"`      // Nama atau nomor urut sheet (1-based), kosong berarti sheet pertama
+	HeaderRow int            `yaml:"header_row"` // Nomor baris header (1-based), default 1
+	Fields    []FieldMapping `yaml:"fields"`
+
+	// HeaderRows jumlah baris header mulai dari header_row. Teks setiap kolom digabung dengan
+	// spasi dan cell merge diisi ke semua kolomnya, misalnya "Harga" di atas "Beli" dan "Jual".
+	HeaderRows int `yaml:"header_rows"`
+
+	// FirstDataRow dan LastDataRow rentang baris data (1-based, inklusif). Kosong berarti
+	// mulai tepat setelah header dan berakhir di baris terakhir sheet.
+	FirstDataRow int `yaml:"first_data_row"`
+	LastDataRow  int `yaml:"last_data_row"`
+
+	// StopAtBlank berhenti membaca di baris data pertama yang kosong seluruhnya
+	StopAtBlank bool `yaml:"stop_at_blank"`
+
+	// SkipPrefixes melewati baris yang cell terisi pertamanya diawali salah satu teks ini
+	// (case-insensitive), misalnya baris footer TOTAL pada laporan POS
+	SkipPrefixes []string `yaml:"skip_prefixes"`
+
+	// FuzzyHeaders mencocokkan header tanpa memperhatikan tanda baca, titik dan spasi ganda
+	FuzzyHeaders bool `yaml:"fuzzy_headers"`
+
+	// Sheets daftar sheet yang diimpor sekaligus, masing-masing boleh punya mapping sendiri.
+	// Jika diisi, Sheet diabaikan.
+	Sheets []SheetMapping `yaml:"sheets"`
+
+	// AllSheets mengimpor semua sheet yang tidak disembunyikan jika Sheets kosong
+	AllSheets bool `yaml:"all_sheets"`
+
+	// SheetField field yang diisi dengan nama sheet, misalnya Spec atau MBuID
+	SheetField string `yaml:"sheet_field"`
+
+	// Input format file input dan cara membaca file CSV/TSV
+	Input InputOptions `yaml:"input"`
+}
+
+// InputOptions pengaturan pembacaan file input. Nilai kosong berarti dideteksi otomatis.
+type InputOptions struct {
+	Format    string `yaml:"format"`    // xlsx, csv atau tsv; kosong berarti dari ekstensi file
+	Delimiter string `yaml:"delimiter"` // Pemisah kolom CSV, misalnya "," ";" "|" atau "\t"
+	Encoding  string `yaml:"encoding"`  // Encoding CSV, misalnya utf-8, utf-16le atau windows-1252
+	Quote     string `yaml:"quote"`     // Karakter quote CSV: '"', "'" atau none
+}
+
+// SheetMapping pengaturan satu sheet pada import multi-sheet
+type SheetMapping struct {
+	Sheet     string            `yaml:"sheet"`      // Nama atau nomor urut sheet (1-based)
+	HeaderRow int               `yaml:"header_row"` // Menimpa header_row profile untuk sheet ini
+	Fields    []FieldMapping    `yaml:"fields"`     // Menimpa fields profile untuk sheet ini
+	Values    map[string]string `yaml:"values"`     // Nilai tetap per field untuk semua baris sheet, misalnya MBuID atau MCat1ID
+
+	HeaderRows   int `yaml:"header_rows"`    // Menimpa header_rows profile untuk sheet ini
+	FirstDataRow int `yaml:"first_data_row"` // Menimpa first_data_row profile untuk sheet ini
+	LastDataRow  int `yaml:"last_data_row"`  // Menimpa last_data_row profile untuk sheet ini
+}
+
+// FieldMapping mapping satu field struct ke header Excel
+type FieldMapping struct {
+	Field    string   `yaml:"field"`    // Nama field struct, misalnya ItemName atau PriceBase
+	Headers  []string `yaml:"headers"`  // Alias header Excel untuk field ini (case-insensitive)
+	Required bool     `yaml:"required"` // Baris ditolak jika kolom kosong dan tidak ada default
+	Type     string   `yaml:"type"`     // string, number, integer atau bool; kosong berarti mengikuti tipe field
+	Default  string   `yaml:"default"`  // Nilai yang dipakai jika kolom kosong atau tidak ada
+}
+
+// Profile mengembalikan mapping profile berdasarkan nama
+func (c *Config) Profile(name string) (MappingProfile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return MappingProfile{}, fmt.Errorf("mapping profile '%s' not found in config", name)
+	}
+	return profile, nil
+}
+
 func LoadConfig(filename string) (*Config, error) {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,7 +11,7 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func ConnectDB(cfg *config.Config) (*sql.DB, error) {
+func ConnectDB(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s timezone=%s",
 		cfg.Database.Host,
 		cfg.Database.Port,
@@ -39,7 +40,7 @@ func ConnectDB(cfg *config.Config) (*sql.DB, error) {
 	}
 
 	// Test connection
-	err = db.Ping()
+	err = db.PingContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to database: %v", err)
 	}
@@ -0,0 +1,44 @@
+-- public.import_runs definition
+-- Tabel ini dibuat otomatis oleh excel-seeder jika belum ada.
+
+-- Drop table
+
+-- DROP TABLE public.import_run_rows;
+-- DROP TABLE public.import_runs;
+
+CREATE TABLE IF NOT EXISTS public.import_runs (
+	id bigserial NOT NULL,
+	file_name varchar(255) NOT NULL,
+	file_sha256 varchar(64) NOT NULL,
+	entity varchar(20) NOT NULL,
+	profile varchar(100) NOT NULL,
+	mode varchar(20) NOT NULL,
+	loader varchar(20) NOT NULL,
+	"operator" varchar(100) NULL,
+	rows_parsed int4 DEFAULT 0 NOT NULL,
+	rows_inserted int4 DEFAULT 0 NOT NULL,
+	rows_rejected int4 DEFAULT 0 NOT NULL,
+	started_at timestamp(0) NOT NULL,
+	finished_at timestamp(0) NULL,
+	outcome varchar(20) NOT NULL,
+	message text NULL,
+	CONSTRAINT import_runs_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_import_runs_file_sha256 ON public.import_runs USING btree (file_sha256);
+ALTER TABLE public.import_runs ADD COLUMN IF NOT EXISTS rolled_back_at timestamp(0) NULL;
+
+-- public.import_run_rows definition
+-- Id baris yang di-insert dan isi lama baris yang di-update oleh setiap run, dipakai oleh perintah rollback.
+
+CREATE TABLE IF NOT EXISTS public.import_run_rows (
+	id bigserial NOT NULL,
+	run_id int8 NOT NULL,
+	table_name varchar(63) NOT NULL,
+	row_id int8 NOT NULL,
+	"action" varchar(10) NOT NULL,
+	before_image jsonb NULL,
+	CONSTRAINT import_run_rows_pkey PRIMARY KEY (id),
+	CONSTRAINT import_run_rows_run_id_fkey FOREIGN KEY (run_id) REFERENCES public.import_runs(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_import_run_rows_run_id ON public.import_run_rows USING btree (run_id);
+CREATE INDEX IF NOT EXISTS idx_import_run_rows_row ON public.import_run_rows USING btree (table_name, row_id);
@@ -0,0 +1,395 @@
+package excel
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"excel-seeder/config"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// csvSampleSize jumlah byte awal file yang dipakai untuk mendeteksi encoding, quote dan delimiter
+const csvSampleSize = 64 * 1024
+
+// csvDelimiters kandidat delimiter yang dicoba saat deteksi, berurutan sesuai prioritas jika sama kuat
+var csvDelimiters = []rune{',', ';', '\t', '|'}
+
+// csvSingleQuoted field yang diapit quote tunggal, misalnya 'ABC';'123'
+var csvSingleQuoted = regexp.MustCompile(`(?m)(^|[,;\t|])'[^'\n]*'([,;\t|]|\r?$)`)
+
+// csvSource file CSV/TSV yang dibaca sebagai workbook dengan satu sheet bernama seperti
+// file-nya. Nomor baris adalah nomor record (1-based), sama seperti saat file dibuka di Excel.
+type csvSource struct {
+	path      string
+	sheet     string
+	encoding  encoding.Encoding
+	delimiter rune
+	quote     rune // 0 berarti tanda quote dibaca sebagai teks biasa
+}
+
+// openCSV membaca awal file untuk menentukan encoding, quote dan delimiter. Opsi yang diisi
+// dipakai apa adanya; sisanya dideteksi dari isi file.
+func openCSV(filename, format string, opts config.InputOptions) (*csvSource, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening CSV file: %v", err)
+	}
+	defer file.Close()
+
+	sample := make([]byte, csvSampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("error reading CSV file: %v", err)
+	}
+	sample = sample[:n]
+	if n == csvSampleSize {
+		// Buang baris terakhir yang mungkin terpotong
+		if i := bytes.LastIndexByte(sample, '\n'); i > 0 {
+			sample = sample[:i+1]
+		}
+	}
+
+	src := &csvSource{path: filename, sheet: csvSheetName(filename)}
+	var encodingName string
+	src.encoding, encodingName, err = csvEncoding(sample, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	text, _, err := transform.String(xunicode.BOMOverride(src.encoding.NewDecoder()), string(sample))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding CSV file as %s: %v", encodingName, err)
+	}
+	if src.quote, err = csvQuote(text, opts.Quote); err != nil {
+		return nil, err
+	}
+	if src.delimiter, err = csvDelimiter(text, format, opts.Delimiter, src.quote); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Reading %s file: delimiter %s, encoding %s, quote %s", strings.ToUpper(format), describeRune(src.delimiter), encodingName, describeRune(src.quote))
+	return src, nil
+}
+
+// csvEncoding mengembalikan encoding dengan nama name, atau mendeteksinya dari sample:
+// BOM UTF-8/UTF-16, UTF-16 tanpa BOM, UTF-8, dan selain itu windows-1252 yang dipakai Excel
+// saat menyimpan CSV di Windows
+func csvEncoding(sample []byte, name string) (encoding.Encoding, string, error) {
+	if name != "" {
+		enc, err := htmlindex.Get(name)
+		if err != nil {
+			return nil, "", fmt.Errorf("unsupported CSV encoding '%s'", name)
+		}
+		canonical, err := htmlindex.Name(enc)
+		if err != nil {
+			canonical = name
+		}
+		return enc, canonical, nil
+	}
+
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return xunicode.UTF8, "utf-8 (BOM)", nil
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return xunicode.UTF16(xunicode.LittleEndian, xunicode.IgnoreBOM), "utf-16le (BOM)", nil
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return xunicode.UTF16(xunicode.BigEndian, xunicode.IgnoreBOM), "utf-16be (BOM)", nil
+	case len(sample) >= 2 && sample[0] != 0 && sample[1] == 0:
+		return xunicode.UTF16(xunicode.LittleEndian, xunicode.IgnoreBOM), "utf-16le", nil
+	case len(sample) >= 2 && sample[0] == 0 && sample[1] != 0:
+		return xunicode.UTF16(xunicode.BigEndian, xunicode.IgnoreBOM), "utf-16be", nil
+	case utf8.Valid(sample):
+		return xunicode.UTF8, "utf-8", nil
+	default:
+		return charmap.Windows1252, "windows-1252", nil
+	}
+}
+
+// csvQuote mengembalikan karakter quote sesuai opsi, atau mendeteksinya dari sample: quote
+// ganda jika ada, quote tunggal jika ada field yang diapit quote tunggal
+func csvQuote(text, name string) (rune, error) {
+	switch name {
+	case `"`:
+		return '"', nil
+	case "'":
+		return '\'', nil
+	case "none":
+		return 0, nil
+	case "":
+	default:
+		return 0, fmt.Errorf(`unsupported CSV quote '%s', use ", ' or none`, name)
+	}
+
+	if !strings.ContainsRune(text, '"') && csvSingleQuoted.MatchString(text) {
+		return '\'', nil
+	}
+	return '"', nil
+}
+
+// csvDelimiter mengembalikan delimiter sesuai opsi, tab untuk TSV, atau mendeteksinya dari
+// sample: kandidat yang jumlahnya per baris paling sering sama, lalu yang paling banyak kolomnya.
+// Baris judul tanpa delimiter tidak mempengaruhi deteksi.
+func csvDelimiter(text, format, name string, quote rune) (rune, error) {
+	switch name {
+	case "":
+	case `\t`, "tab":
+		return '\t', nil
+	default:
+		r, size := utf8.DecodeRuneInString(name)
+		if size != len(name) || r == quote || r == '\n' || r == '\r' {
+			return 0, fmt.Errorf("invalid CSV delimiter '%s', use a single character", name)
+		}
+		return r, nil
+	}
+	if format == FormatTSV {
+		return '\t', nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+		if len(lines) == 50 {
+			break
+		}
+	}
+
+	best, bestLines, bestCount := ',', 0, 0
+	for _, delimiter := range csvDelimiters {
+		frequency := make(map[int]int)
+		for _, line := range lines {
+			if count := countOutsideQuotes(line, delimiter, quote); count > 0 {
+				frequency[count]++
+			}
+		}
+		for count, n := range frequency {
+			if n > bestLines || (n == bestLines && count > bestCount) {
+				best, bestLines, bestCount = delimiter, n, count
+			}
+		}
+	}
+	return best, nil
+}
+
+// countOutsideQuotes menghitung delimiter pada baris yang tidak berada di dalam quote
+func countOutsideQuotes(line string, delimiter, quote rune) int {
+	count, quoted := 0, false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quoted = !quoted
+		case r == delimiter && !quoted:
+			count++
+		}
+	}
+	return count
+}
+
+// describeRune menampilkan delimiter atau quote untuk log
+func describeRune(r rune) string {
+	switch r {
+	case 0:
+		return "none"
+	case '\t':
+		return "tab"
+	default:
+		return "'" + string(r) + "'"
+	}
+}
+
+// csvSheetName nama sheet untuk file CSV: nama file tanpa ekstensi, disesuaikan dengan aturan
+// nama sheet Excel agar file error tetap bisa ditulis
+func csvSheetName(filename string) string {
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if strings.Trim(name, "' ") == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+func (s *csvSource) sheetList() []string {
+	return []string{s.sheet}
+}
+
+func (s *csvSource) sheetVisible(sheet string) bool {
+	return true
+}
+
+// resolveSheet file CSV hanya punya satu sheet, sehingga pilihan sheet apa pun (misalnya
+// sheet: Sheet1 pada profile yang juga dipakai untuk workbook Excel) mengarah ke sheet tersebut
+func (s *csvSource) resolveSheet(sheet string) (string, error) {
+	return s.sheet, nil
+}
+
+// getRows membaca semua baris seperti excelize GetRows: baris kosong di akhir file dibuang
+func (s *csvSource) getRows(sheet string) ([][]string, error) {
+	rows, err := s.rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result [][]string
+	for rows.Next() {
+		row, _ := rows.Columns()
+		result = append(result, row)
+	}
+	if err := rows.Error(); err != nil {
+		return nil, err
+	}
+	for len(result) > 0 && len(result[len(result)-1]) == 0 {
+		result = result[:len(result)-1]
+	}
+	return result, nil
+}
+
+func (s *csvSource) rows(sheet string) (rowIterator, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening CSV file: %v", err)
+	}
+	decoded := transform.NewReader(file, xunicode.BOMOverride(s.encoding.NewDecoder()))
+	return &csvRows{
+		file:   file,
+		reader: csvReader{r: bufio.NewReader(decoded), delimiter: s.delimiter, quote: s.quote},
+	}, nil
+}
+
+func (s *csvSource) mergeCells(sheet string) ([]excelize.MergeCell, error) {
+	return nil, nil
+}
+
+func (s *csvSource) Close() error {
+	return nil
+}
+
+// csvRows iterator record file CSV
+type csvRows struct {
+	file    *os.File
+	reader  csvReader
+	current []string
+	err     error
+}
+
+func (r *csvRows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	r.current, r.err = r.reader.read()
+	return r.err == nil
+}
+
+func (r *csvRows) Columns() ([]string, error) {
+	return r.current, nil
+}
+
+func (r *csvRows) Error() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+func (r *csvRows) Close() error {
+	return r.file.Close()
+}
+
+// csvReader membaca record CSV dengan delimiter dan quote apa pun. Berbeda dengan encoding/csv,
+// pembacaannya longgar seperti Excel: quote di tengah field dibaca sebagai teks, baris kosong
+// tetap menjadi record kosong agar nomor baris sama, dan cell kosong di akhir record dibuang.
+type csvReader struct {
+	r         *bufio.Reader
+	delimiter rune
+	quote     rune
+}
+
+// read membaca satu record; io.EOF jika file sudah habis
+func (c *csvReader) read() ([]string, error) {
+	var (
+		record  []string
+		field   strings.Builder
+		started bool // Field saat ini sudah berisi karakter, sehingga quote dibaca sebagai teks
+		quoted  bool
+		empty   = true
+	)
+	for {
+		r, _, err := c.r.ReadRune()
+		if err == io.EOF {
+			if empty {
+				return nil, io.EOF
+			}
+			return trimRecord(append(record, field.String())), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV file: %v", err)
+		}
+		empty = false
+
+		if quoted {
+			if r != c.quote {
+				field.WriteRune(r)
+				continue
+			}
+			// Quote ganda di dalam field berarti satu karakter quote
+			if next, _, err := c.r.ReadRune(); err == nil {
+				if next == c.quote {
+					field.WriteRune(r)
+					continue
+				}
+				c.r.UnreadRune()
+			}
+			quoted = false
+			continue
+		}
+
+		switch {
+		case r == c.quote && c.quote != 0 && !started:
+			quoted, started = true, true
+		case r == c.delimiter:
+			record = append(record, field.String())
+			field.Reset()
+			started = false
+		case r == '\n' || r == '\r':
+			if r == '\r' {
+				if next, _, err := c.r.ReadRune(); err == nil && next != '\n' {
+					c.r.UnreadRune()
+				}
+			}
+			return trimRecord(append(record, field.String())), nil
+		default:
+			field.WriteRune(r)
+			started = true
+		}
+	}
+}
+
+// trimRecord membuang cell kosong di akhir record, seperti baris yang dibaca excelize
+func trimRecord(record []string) []string {
+	for len(record) > 0 && record[len(record)-1] == "" {
+		record = record[:len(record)-1]
+	}
+	return record
+}
@@ -0,0 +1,257 @@
+package excel
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"excel-seeder/config"
+
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// readCSV membaca semua record input dengan csvReader
+func readCSV(t *testing.T, input string, delimiter, quote rune) [][]string {
+	t.Helper()
+	reader := csvReader{r: bufio.NewReader(strings.NewReader(input)), delimiter: delimiter, quote: quote}
+	var records [][]string
+	for {
+		record, err := reader.read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		delimiter rune
+		quote     rune
+		want      [][]string
+	}{
+		{"simple", "a,b,c\n1,2,3\n", ',', '"', [][]string{{"a", "b", "c"}, {"1", "2", "3"}}},
+		{"no final newline", "a,b\n1,2", ',', '"', [][]string{{"a", "b"}, {"1", "2"}}},
+		{"crlf", "a,b\r\n1,2\r\n", ',', '"', [][]string{{"a", "b"}, {"1", "2"}}},
+		{"cr only", "a,b\r1,2\r", ',', '"', [][]string{{"a", "b"}, {"1", "2"}}},
+		{"quoted delimiter", `"a,b",c` + "\n", ',', '"', [][]string{{"a,b", "c"}}},
+		{"escaped quote", `"say ""hi""",x` + "\n", ',', '"', [][]string{{`say "hi"`, "x"}}},
+		{"embedded newline", "\"line 1\nline 2\",x\ny,z\n", ',', '"', [][]string{{"line 1\nline 2", "x"}, {"y", "z"}}},
+		{"embedded crlf", "\"a\r\nb\",c\r\n", ',', '"', [][]string{{"a\r\nb", "c"}}},
+		{"quote inside field is text", `12" pipe,x` + "\n", ',', '"', [][]string{{`12" pipe`, "x"}}},
+		{"text after closing quote", `"ab"cd,x` + "\n", ',', '"', [][]string{{"abcd", "x"}}},
+		{"empty quoted field", `"",x` + "\n", ',', '"', [][]string{{"", "x"}}},
+		{"blank line keeps row number", "a,b\n\n1,2\n", ',', '"', [][]string{{"a", "b"}, {}, {"1", "2"}}},
+		{"trailing empty cells trimmed", "a,b,,\n,1,,\n", ',', '"', [][]string{{"a", "b"}, {"", "1"}}},
+		{"semicolon", "a;\"1,5\";c\n", ';', '"', [][]string{{"a", "1,5", "c"}}},
+		{"tab", "a\tb c\t\"d\te\"\n", '\t', '"', [][]string{{"a", "b c", "d\te"}}},
+		{"single quote", "'a,b','it''s'\n", ',', '\'', [][]string{{"a,b", "it's"}}},
+		{"no quote", `"a,b"` + "\n", ',', 0, [][]string{{`"a`, `b"`}}},
+		{"unterminated quote", "\"a,b\nc", ',', '"', [][]string{{"a,b\nc"}}},
+		{"empty input", "", ',', '"', nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readCSV(t, tt.input, tt.delimiter, tt.quote); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		sample  []byte
+		option  string
+		want    string
+		wantErr bool
+	}{
+		{"utf-8 bom", []byte("\xEF\xBB\xBFkode,nama\n"), "", "utf-8 (BOM)", false},
+		{"utf-16le bom", []byte("\xFF\xFEk\x00o\x00"), "", "utf-16le (BOM)", false},
+		{"utf-16be bom", []byte("\xFE\xFF\x00k\x00o"), "", "utf-16be (BOM)", false},
+		{"utf-16le without bom", []byte("k\x00o\x00"), "", "utf-16le", false},
+		{"utf-16be without bom", []byte("\x00k\x00o"), "", "utf-16be", false},
+		{"utf-8", []byte("kode,nama\n1,Caf\xC3\xA9\n"), "", "utf-8", false},
+		{"windows-1252", []byte("kode,nama\n1,Caf\xE9\n"), "", "windows-1252", false},
+		{"empty", nil, "", "utf-8", false},
+		{"option", []byte("\xEF\xBB\xBF"), "latin1", "windows-1252", false},
+		{"option utf-16le", nil, "UTF-16LE", "utf-16le", false},
+		{"unknown option", nil, "ebcdic-x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, name, err := csvEncoding(tt.sample, tt.option)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("csvEncoding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if name != tt.want {
+				t.Errorf("csvEncoding() = %s, want %s", name, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVQuote(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		option  string
+		want    rune
+		wantErr bool
+	}{
+		{"no quotes", "a,b\n1,2\n", "", '"', false},
+		{"double quotes", "\"a\",\"b\"\n", "", '"', false},
+		{"single quoted fields", "'a';'b'\n'1';'2'\n", "", '\'', false},
+		{"apostrophe in text", "nama,ket\nJUMA'AT,x\n", "", '"', false},
+		{"double wins over single", "'a',\"b\"\n", "", '"', false},
+		{"option single", "\"a\"\n", "'", '\'', false},
+		{"option none", "\"a\"\n", "none", 0, false},
+		{"invalid option", "", "`", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csvQuote(tt.text, tt.option)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("csvQuote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("csvQuote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		format  string
+		option  string
+		want    rune
+		wantErr bool
+	}{
+		{"comma", "kode,nama,harga\n1,GULA,14000\n", FormatCSV, "", ',', false},
+		{"semicolon with decimal comma", "kode;nama;harga\n1;GULA;\"14000,50\"\n2;TEH;\"9000,25\"\n", FormatCSV, "", ';', false},
+		{"tab", "kode\tnama\n1\tGULA, 1KG\n", FormatCSV, "", '\t', false},
+		{"pipe", "kode|nama|harga\n1|GULA|14000\n", FormatCSV, "", '|', false},
+		{"title row ignored", "DAFTAR BARANG\nkode;nama;harga\n1;GULA;14000\n2;TEH;9000\n", FormatCSV, "", ';', false},
+		{"commas in quoted text", "kode;nama\n1;\"GULA, PASIR, 1KG\"\n2;\"TEH, CELUP\"\n", FormatCSV, "", ';', false},
+		{"no delimiter", "kode\n1\n", FormatCSV, "", ',', false},
+		{"tsv format", "kode,nama\n", FormatTSV, "", '\t', false},
+		{"option tab", "kode,nama\n", FormatCSV, "tab", '\t', false},
+		{"option escaped tab", "kode,nama\n", FormatCSV, `\t`, '\t', false},
+		{"option", "kode,nama\n", FormatCSV, ";", ';', false},
+		{"option too long", "", FormatCSV, ";;", 0, true},
+		{"option is quote", "", FormatCSV, `"`, 0, true},
+		{"option is newline", "", FormatCSV, "\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csvDelimiter(tt.text, tt.format, tt.option, '"')
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("csvDelimiter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("csvDelimiter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenCSV(t *testing.T) {
+	utf16, err := xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM).NewEncoder().String("kode;nama\n1;\"GULA; PASIR\"\n\n2;CAFÉ\n\n")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		opts    config.InputOptions
+		want    [][]string
+	}{
+		{
+			name:    "utf-16le bom semicolon",
+			file:    "barang.csv",
+			content: utf16,
+			want:    [][]string{{"kode", "nama"}, {"1", "GULA; PASIR"}, {}, {"2", "CAFÉ"}},
+		},
+		{
+			name:    "utf-8 bom",
+			file:    "barang.csv",
+			content: "\xEF\xBB\xBFkode,nama\n1,TEH\n",
+			want:    [][]string{{"kode", "nama"}, {"1", "TEH"}},
+		},
+		{
+			name:    "windows-1252",
+			file:    "barang.txt",
+			content: "kode,nama\n1,CAF\xC9\n",
+			want:    [][]string{{"kode", "nama"}, {"1", "CAFÉ"}},
+		},
+		{
+			name:    "tsv",
+			file:    "barang.tsv",
+			content: "kode\tnama\n1\tGULA, 1KG\n",
+			want:    [][]string{{"kode", "nama"}, {"1", "GULA, 1KG"}},
+		},
+		{
+			name:    "options",
+			file:    "barang.csv",
+			content: "kode|'nama'\n1|'JUMA''AT'\n",
+			opts:    config.InputOptions{Delimiter: "|", Quote: "'", Encoding: "utf-8"},
+			want:    [][]string{{"kode", "nama"}, {"1", "JUMA'AT"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			src, err := openSource(path, tt.opts)
+			if err != nil {
+				t.Fatalf("openSource: %v", err)
+			}
+			defer src.Close()
+			if sheets := src.sheetList(); len(sheets) != 1 || sheets[0] != "barang" {
+				t.Errorf("sheetList() = %q, want [barang]", sheets)
+			}
+			rows, err := src.getRows("barang")
+			if err != nil {
+				t.Fatalf("getRows: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %q, want %q", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVSheetName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"data/barang.csv", "barang"},
+		{"stok [gudang].csv", "stok _gudang_"},
+		{"'.csv", "Sheet1"},
+		{"nama file yang sangat panjang sekali untuk sheet.csv", "nama file yang sangat panjang s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := csvSheetName(tt.file); got != tt.want {
+				t.Errorf("csvSheetName(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
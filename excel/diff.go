@@ -0,0 +1,361 @@
+package excel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"excel-seeder/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Status baris pada hasil diff
+const (
+	DiffNew     = "new"     // Key belum ada di tabel, akan di-insert
+	DiffChanged = "changed" // Key sudah ada dan minimal satu field berbeda
+	DiffAbsent  = "absent"  // Baris di tabel yang tidak ada di workbook
+)
+
+// FieldChange satu field yang nilainya berbeda antara tabel dan workbook
+type FieldChange struct {
+	Field  string // Field struct
+	Column string // Kolom tabel, misalnya price_base
+	Old    string // Nilai di tabel
+	New    string // Nilai di workbook
+}
+
+// DiffRow satu baris hasil diff
+type DiffRow struct {
+	Status  string
+	Key     string        // Nilai natural key (barcode atau code)
+	Sheet   string        // Sheet asal baris; kosong untuk DiffAbsent
+	Row     int           // Nomor baris di workbook; 0 untuk DiffAbsent
+	ID      int64         // id di tabel; 0 untuk DiffNew
+	Name    string        // Nama item, dari workbook atau dari tabel untuk DiffAbsent
+	Changes []FieldChange // Field yang berubah, hanya untuk DiffChanged
+}
+
+// DiffResult hasil perbandingan workbook dengan isi tabel
+type DiffResult struct {
+	Key       string // Kolom natural key yang dipakai untuk mencocokkan baris
+	Rows      []DiffRow
+	New       int
+	Changed   int
+	Unchanged int
+	Absent    int
+}
+
+// DiffOptions mengatur perbandingan workbook dengan tabel
+type DiffOptions struct {
+	// Key natural key untuk mencocokkan baris: barcode (default) atau code
+	Key string
+
+	// Suppliers dipakai untuk membandingkan kolom supplier berdasarkan m_supp_id.
+	// Nil berarti kode/nama supplier dibandingkan sebagai teks.
+	Suppliers *models.SupplierLookup
+}
+
+// diffKeyFields field struct untuk setiap natural key item
+var diffKeyFields = map[string]string{
+	"barcode": "Barcode",
+	"code":    "Code",
+}
+
+// DiffMItems membandingkan items hasil parse workbook dengan isi m_item. Hanya field yang
+// terisi dari workbook pada sheet asal item yang dibandingkan, sesuai mapping sheet tersebut
+// di validation, dan nilai kosong di workbook dilewati: upsert tidak menimpa kolom dengan NULL,
+// jadi kolom tersebut tidak akan berubah. Item workbook tanpa key dianggap baru karena tidak
+// bisa dicocokkan.
+func DiffMItems(items, current []models.MItem, validation *ValidationResult, opts DiffOptions) (*DiffResult, error) {
+	if opts.Key == "" {
+		opts.Key = "barcode"
+	}
+	keyField, ok := diffKeyFields[opts.Key]
+	if !ok {
+		return nil, fmt.Errorf("invalid diff key '%s', use barcode or code", opts.Key)
+	}
+
+	sheetFields := make(map[string][]comparedField)
+	for _, item := range items {
+		if _, ok := sheetFields[item.SourceSheet]; ok {
+			continue
+		}
+		fields, err := diffFields(validation, item.SourceSheet)
+		if err != nil {
+			return nil, err
+		}
+		sheetFields[item.SourceSheet] = fields
+	}
+
+	result := &DiffResult{Key: opts.Key}
+	existing := make(map[string]*models.MItem, len(current))
+	for i := range current {
+		key := diffKey(reflect.ValueOf(current[i]).FieldByName(keyField))
+		if _, duplicate := existing[key]; key != "" && !duplicate {
+			existing[key] = &current[i]
+		}
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		key := diffKey(reflect.ValueOf(item).FieldByName(keyField))
+		row := DiffRow{Key: key, Sheet: item.SourceSheet, Row: item.SourceRow, Name: item.ItemName}
+		old, ok := existing[key]
+		if key == "" || !ok {
+			row.Status = DiffNew
+			result.New++
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		seen[key] = true
+		row.ID = old.ID
+
+		oldValue, newValue := reflect.ValueOf(*old), reflect.ValueOf(item)
+		for _, field := range sheetFields[item.SourceSheet] {
+			if value := newValue.Field(field.index); value.Kind() == reflect.Ptr && value.IsNil() {
+				continue
+			}
+			var change FieldChange
+			var changed bool
+			if field.name == "SupplierName" {
+				change, changed = diffSupplier(*old, item, opts.Suppliers)
+			} else {
+				change.Old = diffValue(oldValue.Field(field.index))
+				change.New = diffValue(newValue.Field(field.index))
+				changed = change.Old != change.New
+			}
+			if changed {
+				change.Field, change.Column = field.name, field.column
+				row.Changes = append(row.Changes, change)
+			}
+		}
+		if len(row.Changes) == 0 {
+			result.Unchanged++
+			continue
+		}
+		row.Status = DiffChanged
+		result.Changed++
+		result.Rows = append(result.Rows, row)
+	}
+
+	for _, old := range current {
+		key := diffKey(reflect.ValueOf(old).FieldByName(keyField))
+		if key != "" && seen[key] {
+			continue
+		}
+		result.Absent++
+		result.Rows = append(result.Rows, DiffRow{Status: DiffAbsent, Key: key, ID: old.ID, Name: old.ItemName})
+	}
+
+	return result, nil
+}
+
+// comparedField field MItem yang dibandingkan beserta kolom tabelnya
+type comparedField struct {
+	index  int
+	name   string
+	column string
+}
+
+// diffFields field yang terisi dari workbook pada sheet, berurutan sesuai struct MItem
+func diffFields(validation *ValidationResult, sheet string) ([]comparedField, error) {
+	target := reflect.TypeOf(models.MItem{})
+	var fields []comparedField
+	for name := range validation.columns[sheet] {
+		if !validation.bound(sheet, name) {
+			continue
+		}
+		structField, ok := target.FieldByName(name)
+		if !ok || len(structField.Index) != 1 {
+			return nil, fmt.Errorf("unknown field '%s' for %s", name, target.Name())
+		}
+		column := structField.Tag.Get("db")
+		if name == "SupplierName" {
+			column = "m_supp_id"
+		}
+		fields = append(fields, comparedField{structField.Index[0], name, column})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].index < fields[j].index })
+	return fields, nil
+}
+
+// diffSupplier membandingkan supplier item. Dengan lookup, supplier workbook di-resolve ke
+// m_supp_id lalu dibandingkan dengan m_supp_id di tabel; nilai yang ditampilkan tetap kode/nama.
+func diffSupplier(old, item models.MItem, suppliers *models.SupplierLookup) (FieldChange, bool) {
+	change := FieldChange{Old: diffValue(reflect.ValueOf(old.SupplierName)), New: diffValue(reflect.ValueOf(item.SupplierName))}
+	if suppliers == nil || item.SupplierName == nil {
+		return change, !strings.EqualFold(change.Old, change.New)
+	}
+
+	id, found, err := suppliers.Find(*item.SupplierName)
+	switch {
+	case err != nil:
+		change.New += " (ambigu)"
+		return change, true
+	case !found:
+		change.New += " (tidak ada di m_supp)"
+		return change, true
+	}
+	return change, old.MSuppID == nil || *old.MSuppID != id
+}
+
+// diffKey nilai natural key sebagai teks, tanpa spasi di awal dan akhir
+func diffKey(value reflect.Value) string {
+	return strings.TrimSpace(diffValue(value))
+}
+
+// diffValue mengubah nilai field menjadi teks untuk dibandingkan dan ditampilkan;
+// pointer nil menjadi teks kosong
+func diffValue(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
+
+var diffReportHeaders = []string{"Status", "Key", "Sheet", "Row", "ID", "Name", "Column", "Old", "New"}
+
+// records baris laporan untuk satu DiffRow: satu baris per field yang berubah, atau satu
+// baris tanpa kolom perubahan untuk baris baru dan baris yang tidak ada di workbook
+func (row DiffRow) records() [][]interface{} {
+	base := []interface{}{row.Status, row.Key, row.Sheet, nil, nil, row.Name}
+	if row.Row > 0 {
+		base[3] = row.Row
+	}
+	if row.ID > 0 {
+		base[4] = row.ID
+	}
+	if len(row.Changes) == 0 {
+		return [][]interface{}{append(base, "", "", "")}
+	}
+	records := make([][]interface{}, len(row.Changes))
+	for i, change := range row.Changes {
+		records[i] = append(append([]interface{}{}, base...), change.Column, change.Old, change.New)
+	}
+	return records
+}
+
+// WriteDiffReport menulis hasil diff ke file CSV atau XLSX sesuai ekstensi path
+func WriteDiffReport(result *DiffResult, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return writeDiffCSV(result, path)
+	case ".xlsx":
+		return writeDiffXLSX(result, path)
+	default:
+		return fmt.Errorf("unsupported diff report format '%s', use .csv or .xlsx", filepath.Ext(path))
+	}
+}
+
+func writeDiffCSV(result *DiffResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating diff report file: %v", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(diffReportHeaders); err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		for _, record := range row.records() {
+			values := make([]string, len(record))
+			for i, value := range record {
+				if value != nil {
+					values[i] = fmt.Sprint(value)
+				}
+			}
+			if err := w.Write(values); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeDiffXLSX(result *DiffResult, path string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Diff"
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	// Hijau untuk baris baru, kuning untuk yang berubah, merah untuk yang tidak ada di workbook
+	statusStyles := make(map[string]int)
+	for status, colors := range map[string][2]string{
+		DiffNew:     {"C6EFCE", "006100"},
+		DiffChanged: {"FFEB9C", "9C5700"},
+		DiffAbsent:  {"FFC7CE", "9C0006"},
+	} {
+		statusStyles[status], err = f.NewStyle(&excelize.Style{
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{colors[0]}},
+			Font: &excelize.Font{Color: colors[1]},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	headers := make([]interface{}, len(diffReportHeaders))
+	for i, header := range diffReportHeaders {
+		headers[i] = header
+	}
+	if err := sw.SetRow("A1", headers, excelize.RowOpts{StyleID: headerStyle}); err != nil {
+		return err
+	}
+
+	rowNum := 2
+	for _, row := range result.Rows {
+		for _, record := range row.records() {
+			cells := make([]interface{}, len(record))
+			for i, value := range record {
+				cells[i] = excelize.Cell{StyleID: statusStyles[row.Status], Value: value}
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+			if err := sw.SetRow(cell, cells); err != nil {
+				return err
+			}
+			rowNum++
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("error saving diff report file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package excel
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"excel-seeder/config"
+	"excel-seeder/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// testSheet satu sheet workbook test
+type testSheet struct {
+	name string
+	rows [][]interface{}
+}
+
+// writeTestWorkbook membuat workbook dari sheets di direktori sementara test
+func writeTestWorkbook(t *testing.T, sheets ...testSheet) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	for i, sheet := range sheets {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.name); err != nil {
+				t.Fatalf("SetSheetName: %v", err)
+			}
+		} else if _, err := f.NewSheet(sheet.name); err != nil {
+			t.Fatalf("NewSheet: %v", err)
+		}
+		for j, row := range sheet.rows {
+			cell, _ := excelize.CoordinatesToCellName(1, j+1)
+			if err := f.SetSheetRow(sheet.name, cell, &row); err != nil {
+				t.Fatalf("SetSheetRow: %v", err)
+			}
+		}
+	}
+	path := filepath.Join(t.TempDir(), "items.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs: %v", err)
+	}
+	return path
+}
+
+func TestDiffMItemsSkipsUnmappedAndEmptyFields(t *testing.T) {
+	// Workbook tanpa kolom partai3, dengan kolom supplier yang kosong
+	path := writeTestWorkbook(t, testSheet{"Sheet1", [][]interface{}{
+		{"Kode Barang", "Nama Barang", "HargaBeli", "HargaJual", "Supplier"},
+		{"8998866200301", "INDOMIE GORENG 85G", 2500, 3000, ""},
+	}})
+	items, validation, err := ParseExcelToMItems(context.Background(), path, DefaultItemProfile())
+	if err != nil {
+		t.Fatalf("ParseExcelToMItems: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("parsed %d items, want 1", len(items))
+	}
+
+	zero, supplier := 0.0, "SUP01"
+	tests := []struct {
+		name      string
+		update    func(item *models.MItem)
+		changed   int
+		unchanged int
+	}{
+		{
+			name: "unchanged row",
+			update: func(item *models.MItem) {
+				item.Wholesale3MinQty = &zero
+				item.SupplierName = &supplier
+			},
+			unchanged: 1,
+		},
+		{
+			name: "changed mapped field",
+			update: func(item *models.MItem) {
+				item.PriceBase = 2400
+			},
+			changed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := items[0]
+			current.ID = 1
+			tt.update(&current)
+
+			result, err := DiffMItems(items, []models.MItem{current}, validation, DiffOptions{})
+			if err != nil {
+				t.Fatalf("DiffMItems: %v", err)
+			}
+			if result.Changed != tt.changed || result.Unchanged != tt.unchanged {
+				t.Errorf("changed=%d unchanged=%d, want changed=%d unchanged=%d (rows %+v)",
+					result.Changed, result.Unchanged, tt.changed, tt.unchanged, result.Rows)
+			}
+		})
+	}
+}
+
+func TestDiffMItemsUsesSheetMapping(t *testing.T) {
+	path := writeTestWorkbook(t,
+		testSheet{"Eceran", [][]interface{}{
+			{"Kode Barang", "Nama Barang", "HargaBeli"},
+			{"1001", "GULA 1KG", 14000},
+		}},
+		testSheet{"Grosir", [][]interface{}{
+			{"Kode", "Nama", "Harga", "Qty Grosir"},
+			{"1002", "GULA 50KG", 650000, 24},
+		}},
+	)
+	profile := DefaultItemProfile()
+	profile.Sheets = []config.SheetMapping{
+		{Sheet: "Eceran"},
+		{
+			Sheet: "Grosir",
+			Fields: []config.FieldMapping{
+				{Field: "Barcode", Headers: []string{"Kode"}},
+				{Field: "ItemName", Headers: []string{"Nama"}, Required: true},
+				{Field: "PriceBase", Headers: []string{"Harga"}, Required: true},
+				{Field: "Wholesale3MinQty", Headers: []string{"Qty Grosir"}},
+			},
+			Values: map[string]string{"Spec": "GROSIR"},
+		},
+	}
+	items, validation, err := ParseExcelToMItems(context.Background(), path, profile)
+	if err != nil {
+		t.Fatalf("ParseExcelToMItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("parsed %d items, want 2", len(items))
+	}
+
+	// Wholesale3MinQty hanya di-mapping di sheet Grosir, dan Spec hanya diisi nilai tetap di sheet
+	// Grosir sehingga tidak ada di fields profile
+	oldQty, retailQty, spec := 12.0, 6.0, "ECERAN"
+	current := make([]models.MItem, len(items))
+	copy(current, items)
+	current[0].ID, current[0].Wholesale3MinQty = 1, &retailQty
+	current[1].ID, current[1].Wholesale3MinQty, current[1].Spec = 2, &oldQty, &spec
+
+	result, err := DiffMItems(items, current, validation, DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffMItems: %v", err)
+	}
+	if result.Changed != 1 || result.Unchanged != 1 {
+		t.Fatalf("changed=%d unchanged=%d, want changed=1 unchanged=1 (rows %+v)", result.Changed, result.Unchanged, result.Rows)
+	}
+	row := result.Rows[0]
+	want := []FieldChange{
+		{Field: "Spec", Column: "spec", Old: "ECERAN", New: "GROSIR"},
+		{Field: "Wholesale3MinQty", Column: "wholesale_3_min_qty", Old: "12", New: "24"},
+	}
+	if row.Sheet != "Grosir" || !reflect.DeepEqual(row.Changes, want) {
+		t.Errorf("changed row %s %+v, want Grosir %+v", row.Sheet, row.Changes, want)
+	}
+}
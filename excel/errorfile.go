@@ -0,0 +1,234 @@
+package excel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"excel-seeder/config"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrorColumnHeader header kolom tambahan berisi pesan error pada file error
+const ErrorColumnHeader = "Error"
+
+// WriteErrorWorkbook menulis salinan sheet input yang hanya berisi baris yang ditolak (dan
+// baris yang dilewati karena semua kolom yang di-mapping kosong), ditambah kolom Error dan highlight merah pada cell yang bermasalah. Baris judul dan
+// header tetap disalin sehingga file yang sudah diperbaiki bisa langsung diimpor ulang
+// dengan profile yang sama. Pada import multi-sheet setiap sheet yang dibaca disalin ke
+// sheet dengan nama yang sama.
+func WriteErrorWorkbook(filename string, profile config.MappingProfile, result *ValidationResult, outputPath string) error {
+	in, err := openSource(filename, profile.Input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	sheets, err := selectSheets(in, profile)
+	if err != nil {
+		return err
+	}
+
+	// Kelompokkan issue error per sheet dan baris. Baris yang dilewati karena semua kolom
+	// yang di-mapping kosong ikut disalin agar bisa dilengkapi.
+	sheetIssues := make(map[string]map[int][]ValidationIssue)
+	for _, issue := range result.Issues {
+		if issue.Severity != SeverityError && issue.Rule != RuleBlank {
+			continue
+		}
+		if sheetIssues[issue.Sheet] == nil {
+			sheetIssues[issue.Sheet] = make(map[int][]ValidationIssue)
+		}
+		sheetIssues[issue.Sheet][issue.Row] = append(sheetIssues[issue.Sheet][issue.Row], issue)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	var styles errorStyles
+	styles.header, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	styles.errorCell, err = f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+		Font: &excelize.Font{Color: "9C0006"},
+	})
+	if err != nil {
+		return err
+	}
+	styles.errorText, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "9C0006"}})
+	if err != nil {
+		return err
+	}
+
+	for i, sheet := range sheets {
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), sheet.name)
+		} else {
+			_, err = f.NewSheet(sheet.name)
+		}
+		if err != nil {
+			return err
+		}
+
+		layout, err := newSheetLayout(sheet.profile)
+		if err != nil {
+			return err
+		}
+		lines, rejected, err := readErrorRows(in, sheet.name, layout, sheetIssues[sheet.name])
+		if err != nil {
+			return err
+		}
+		merges, err := layout.headerMerges(in, sheet.name)
+		if err != nil {
+			return err
+		}
+		if err := writeErrorSheet(f, sheet.name, lines, rejected, layout, merges, sheetIssues[sheet.name], styles); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SaveAs(outputPath); err != nil {
+		return fmt.Errorf("error saving error workbook: %v", err)
+	}
+	return nil
+}
+
+// errorStyles style yang dipakai file error
+type errorStyles struct {
+	header    int
+	errorCell int
+	errorText int
+}
+
+// readErrorRows membaca baris sebelum data (judul dan header) dan baris yang ditolak dari sheet
+// baris demi baris, sehingga baris lain tidak pernah disimpan di memory. Pembacaan berhenti
+// setelah baris terakhir yang dibutuhkan.
+func readErrorRows(src source, sheet string, layout sheetLayout, rowIssues map[int][]ValidationIssue) ([][]string, map[int][]string, error) {
+	lastRow := layout.firstDataRow - 1
+	for row := range rowIssues {
+		if row > lastRow {
+			lastRow = row
+		}
+	}
+
+	rows, err := src.rows(sheet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading Excel rows: %v", err)
+	}
+	defer rows.Close()
+
+	var lines [][]string
+	rejected := make(map[int][]string, len(rowIssues))
+	for rowNum := 1; rowNum <= lastRow && rows.Next(); rowNum++ {
+		row, err := rows.Columns()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading Excel row %d: %v", rowNum, err)
+		}
+		if rowNum < layout.firstDataRow {
+			lines = append(lines, row)
+		} else if _, ok := rowIssues[rowNum]; ok {
+			rejected[rowNum] = row
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return nil, nil, fmt.Errorf("error reading Excel rows: %v", err)
+	}
+	return lines, rejected, nil
+}
+
+// writeErrorSheet menulis baris sebelum data (lines: judul dan header) dan baris yang ditolak
+// (rejected, per nomor baris) dari satu sheet input. Baris yang ditolak ditulis mulai
+// first_data_row, sehingga tetap berada di rentang data profile. Cell merge di baris header
+// ikut disalin agar gabungan header sama.
+func writeErrorSheet(f *excelize.File, sheetName string, lines [][]string, rejected map[int][]string, layout sheetLayout, merges []excelize.MergeCell, rowIssues map[int][]ValidationIssue, styles errorStyles) error {
+	rejectedRows := make([]int, 0, len(rowIssues))
+	for row := range rowIssues {
+		rejectedRows = append(rejectedRows, row)
+	}
+	sort.Ints(rejectedRows)
+
+	// Kolom Error diletakkan setelah kolom terakhir yang terisi
+	errorColumn := 0
+	for _, row := range lines {
+		errorColumn = max(errorColumn, len(row))
+	}
+	for _, row := range rejected {
+		errorColumn = max(errorColumn, len(row))
+	}
+
+	// Salin baris judul dan header
+	outRow := 1
+	for i := range lines {
+		if err := writeSheetRow(f, sheetName, outRow, lines[i]); err != nil {
+			return err
+		}
+		outRow++
+	}
+	for _, merge := range merges {
+		_, endRow, err := excelize.CellNameToCoordinates(merge.GetEndAxis())
+		if err != nil || endRow >= layout.firstDataRow {
+			continue
+		}
+		if err := f.MergeCell(sheetName, merge.GetStartAxis(), merge.GetEndAxis()); err != nil {
+			return err
+		}
+	}
+	outRow = layout.firstDataRow
+	headerCell, _ := excelize.CoordinatesToCellName(errorColumn+1, layout.headerRow)
+	if err := f.SetCellValue(sheetName, headerCell, ErrorColumnHeader); err != nil {
+		return err
+	}
+	if err := f.SetRowStyle(sheetName, layout.headerRow, layout.lastHeaderRow(), styles.header); err != nil {
+		return err
+	}
+
+	// Salin baris yang ditolak beserta pesan errornya
+	for _, rowNum := range rejectedRows {
+		row, ok := rejected[rowNum]
+		if !ok {
+			continue
+		}
+		if err := writeSheetRow(f, sheetName, outRow, row); err != nil {
+			return err
+		}
+
+		messages := make([]string, 0, len(rowIssues[rowNum]))
+		for _, issue := range rowIssues[rowNum] {
+			messages = append(messages, issue.Message)
+			if issue.Cell == "" {
+				continue
+			}
+			column, _, err := excelize.CellNameToCoordinates(issue.Cell)
+			if err != nil {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(column, outRow)
+			if err := f.SetCellStyle(sheetName, cell, cell, styles.errorCell); err != nil {
+				return err
+			}
+		}
+
+		errorCell, _ := excelize.CoordinatesToCellName(errorColumn+1, outRow)
+		if err := f.SetCellValue(sheetName, errorCell, strings.Join(messages, "; ")); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheetName, errorCell, errorCell, styles.errorText); err != nil {
+			return err
+		}
+		outRow++
+	}
+	return nil
+}
+
+// writeSheetRow menulis satu baris nilai mentah mulai dari kolom A
+func writeSheetRow(f *excelize.File, sheetName string, rowNum int, row []string) error {
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+	return f.SetSheetRow(sheetName, cell, &values)
+}
@@ -0,0 +1,58 @@
+package excel
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteErrorWorkbook(t *testing.T) {
+	path := writeTestWorkbook(t, testSheet{"Barang", [][]interface{}{
+		{"DAFTAR BARANG"},
+		{"Kode Barang", "Nama Barang", "HargaBeli"},
+		{"1001", "GULA 1KG", 14000},
+		{"1002", "", 15000},
+		{"1003", "TEH 250G", 9000},
+		{"1004", "KOPI 200G", "abc", "catatan"},
+		{"", "", "", "stok habis"},
+		{"1005", "SABUN", 4000},
+	}})
+	profile := DefaultItemProfile()
+	profile.HeaderRow = 2
+
+	_, validation, err := ParseExcelToMItems(context.Background(), path, profile)
+	if err != nil {
+		t.Fatalf("ParseExcelToMItems: %v", err)
+	}
+	if validation.RowsRejected != 2 {
+		t.Fatalf("rejected %d rows, want 2", validation.RowsRejected)
+	}
+
+	output := filepath.Join(t.TempDir(), "errors.xlsx")
+	if err := WriteErrorWorkbook(path, profile, validation, output); err != nil {
+		t.Fatalf("WriteErrorWorkbook: %v", err)
+	}
+
+	f, err := excelize.OpenFile(output)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Barang")
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	want := [][]string{
+		{"DAFTAR BARANG"},
+		{"Kode Barang", "Nama Barang", "HargaBeli", "", ErrorColumnHeader},
+		{"1002", "", "15000", "", "ItemName is required"},
+		{"1004", "KOPI 200G", "abc", "catatan", "invalid PriceBase 'abc': expected number"},
+		{"", "", "", "stok habis", "all mapped columns are empty, row skipped"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("error workbook rows =\n%q\nwant\n%q", rows, want)
+	}
+}
@@ -0,0 +1,102 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+
+	"excel-seeder/config"
+	"excel-seeder/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ExportMItems menulis items ke workbook dengan header dari mapping profile, sehingga
+// file hasil export bisa diedit lalu diimpor ulang dengan profile yang sama
+func ExportMItems(items []models.MItem, profile config.MappingProfile, outputPath string) error {
+	return exportRecords(items, profile, outputPath)
+}
+
+// ExportMSupps sama seperti ExportMItems untuk data supplier
+func ExportMSupps(supps []models.MSupp, profile config.MappingProfile, outputPath string) error {
+	return exportRecords(supps, profile, outputPath)
+}
+
+// exportRecords menulis satu kolom per field profile (header = alias pertama) dan satu
+// baris per record. Header ditulis di header_row profile; baris di atasnya dibiarkan kosong.
+func exportRecords[T any](records []T, profile config.MappingProfile, outputPath string) error {
+	headerRow, err := headerRowNumber(profile)
+	if err != nil {
+		return err
+	}
+
+	var zero T
+	target := reflect.TypeOf(zero)
+	headers := make([]interface{}, len(profile.Fields))
+	fields := make([]int, len(profile.Fields))
+	for i, mapping := range profile.Fields {
+		structField, ok := target.FieldByName(mapping.Field)
+		if !ok || len(structField.Index) != 1 {
+			return fmt.Errorf("unknown field '%s' for %s", mapping.Field, target.Name())
+		}
+		fields[i] = structField.Index[0]
+		headers[i] = mapping.Field
+		if len(mapping.Headers) > 0 {
+			headers[i] = mapping.Headers[0]
+		}
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	sheetName := profile.Sheet
+	if sheetName == "" {
+		sheetName = f.GetSheetName(0)
+	}
+	if err := f.SetSheetName(f.GetSheetName(0), sheetName); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return err
+	}
+	cell, _ := excelize.CoordinatesToCellName(1, headerRow)
+	if err := sw.SetRow(cell, headers, excelize.RowOpts{StyleID: headerStyle}); err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		value := reflect.ValueOf(record)
+		row := make([]interface{}, len(fields))
+		for j, index := range fields {
+			row[j] = cellValue(value.Field(index))
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, headerRow+i+1)
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	if err := f.SaveAs(outputPath); err != nil {
+		return fmt.Errorf("error saving export workbook: %v", err)
+	}
+	return nil
+}
+
+// cellValue mengambil nilai field untuk ditulis ke cell; pointer nil menjadi cell kosong
+func cellValue(field reflect.Value) interface{} {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	return field.Interface()
+}
@@ -0,0 +1,178 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+
+	"excel-seeder/config"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetLayout letak blok header dan rentang baris data pada satu sheet. Semua nomor baris
+// 1-based seperti di Excel.
+type sheetLayout struct {
+	headerRow    int // Baris header pertama
+	headerRows   int // Jumlah baris header yang digabung
+	firstDataRow int
+	lastDataRow  int // 0 berarti sampai baris terakhir sheet
+	stopAtBlank  bool
+	skipPrefixes []string
+}
+
+// newSheetLayout membaca letak header dan data dari profile beserta nilai default-nya
+func newSheetLayout(profile config.MappingProfile) (sheetLayout, error) {
+	headerRow, err := headerRowNumber(profile)
+	if err != nil {
+		return sheetLayout{}, err
+	}
+	layout := sheetLayout{
+		headerRow:    headerRow,
+		headerRows:   profile.HeaderRows,
+		firstDataRow: profile.FirstDataRow,
+		lastDataRow:  profile.LastDataRow,
+		stopAtBlank:  profile.StopAtBlank,
+	}
+	for _, prefix := range profile.SkipPrefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			layout.skipPrefixes = append(layout.skipPrefixes, strings.ToUpper(prefix))
+		}
+	}
+
+	if layout.headerRows == 0 {
+		layout.headerRows = 1
+	}
+	if layout.headerRows < 1 {
+		return sheetLayout{}, fmt.Errorf("invalid header_rows %d", profile.HeaderRows)
+	}
+	if layout.firstDataRow == 0 {
+		layout.firstDataRow = layout.lastHeaderRow() + 1
+	}
+	if layout.firstDataRow <= layout.lastHeaderRow() {
+		return sheetLayout{}, fmt.Errorf("first_data_row %d must be after the header rows (%d-%d)", layout.firstDataRow, layout.headerRow, layout.lastHeaderRow())
+	}
+	if layout.lastDataRow < 0 || (layout.lastDataRow > 0 && layout.lastDataRow < layout.firstDataRow) {
+		return sheetLayout{}, fmt.Errorf("invalid last_data_row %d, first data row is %d", layout.lastDataRow, layout.firstDataRow)
+	}
+	return layout, nil
+}
+
+// lastHeaderRow baris header terakhir
+func (l sheetLayout) lastHeaderRow() int {
+	return l.headerRow + l.headerRows - 1
+}
+
+// isHeaderRow memeriksa apakah rowNum termasuk blok header
+func (l sheetLayout) isHeaderRow(rowNum int) bool {
+	return rowNum >= l.headerRow && rowNum <= l.lastHeaderRow()
+}
+
+// afterData memeriksa apakah rowNum melewati last_data_row
+func (l sheetLayout) afterData(rowNum int) bool {
+	return l.lastDataRow > 0 && rowNum > l.lastDataRow
+}
+
+// skipPrefix mengembalikan prefix skip_prefixes yang cocok dengan cell terisi pertama baris
+func (l sheetLayout) skipPrefix(row []string) (string, bool) {
+	for _, value := range row {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		for _, prefix := range l.skipPrefixes {
+			if strings.HasPrefix(value, prefix) {
+				return prefix, true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// headers menggabungkan baris-baris blok header (lines[0] adalah baris headerRow) menjadi
+// satu header per kolom. Untuk header lebih dari satu baris, cell merge diisi ke semua kolom
+// dan barisnya lebih dulu sehingga judul grup ikut di setiap sub-header; bagian yang sama
+// berurutan (merge vertikal) hanya ditulis sekali.
+func (l sheetLayout) headers(lines [][]string, merges []excelize.MergeCell) []string {
+	if l.headerRows == 1 {
+		if len(lines) == 0 {
+			return nil
+		}
+		return lines[0]
+	}
+
+	filled := make([][]string, l.headerRows)
+	for i := range filled {
+		if i < len(lines) {
+			filled[i] = append([]string(nil), lines[i]...)
+		}
+	}
+	for _, merge := range merges {
+		startCol, startRow, err := excelize.CellNameToCoordinates(merge.GetStartAxis())
+		if err != nil {
+			continue
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(merge.GetEndAxis())
+		if err != nil {
+			continue
+		}
+		for row := startRow; row <= endRow; row++ {
+			if !l.isHeaderRow(row) {
+				continue
+			}
+			line := filled[row-l.headerRow]
+			for len(line) < endCol {
+				line = append(line, "")
+			}
+			for col := startCol; col <= endCol; col++ {
+				line[col-1] = merge.GetCellValue()
+			}
+			filled[row-l.headerRow] = line
+		}
+	}
+
+	width := 0
+	for _, line := range filled {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	headers := make([]string, width)
+	for col := range headers {
+		var parts []string
+		for _, line := range filled {
+			if col >= len(line) {
+				continue
+			}
+			part := strings.TrimSpace(line[col])
+			if part == "" || (len(parts) > 0 && parts[len(parts)-1] == part) {
+				continue
+			}
+			parts = append(parts, part)
+		}
+		headers[col] = strings.Join(parts, " ")
+	}
+	return headers
+}
+
+// headerMerges membaca cell merge sheet, hanya jika header lebih dari satu baris
+func (l sheetLayout) headerMerges(src source, sheet string) ([]excelize.MergeCell, error) {
+	if l.headerRows == 1 {
+		return nil, nil
+	}
+	merges, err := src.mergeCells(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("error reading merged cells: %v", err)
+	}
+	return merges, nil
+}
+
+// isBlankRow memeriksa apakah semua cell baris kosong
+func isBlankRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
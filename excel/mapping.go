@@ -0,0 +1,476 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"excel-seeder/config"
+)
+
+// Tipe nilai yang didukung FieldMapping.Type
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBool    = "bool"
+)
+
+// DefaultItemProfile membuat mapping profile item dari ExcelHeaderMapping, RequiredFields dan DefaultValues
+func DefaultItemProfile() config.MappingProfile {
+	return profileFromMapping("item", ExcelHeaderMapping, RequiredFields, DefaultValues)
+}
+
+// DefaultSupplierProfile membuat mapping profile supplier dari SupplierHeaderMapping dan SupplierRequiredFields
+func DefaultSupplierProfile() config.MappingProfile {
+	return profileFromMapping("supplier", SupplierHeaderMapping, SupplierRequiredFields, nil)
+}
+
+// profileFromMapping menyusun MappingProfile dari header mapping bawaan (header -> field)
+func profileFromMapping(entity string, headerMapping map[string]string, required map[string]bool, defaults map[string]string) config.MappingProfile {
+	headersByField := make(map[string][]string)
+	for header, field := range headerMapping {
+		headersByField[field] = append(headersByField[field], header)
+	}
+
+	fieldNames := make([]string, 0, len(headersByField))
+	for field, headers := range headersByField {
+		sort.Strings(headers)
+		fieldNames = append(fieldNames, field)
+	}
+	sort.Strings(fieldNames)
+
+	profile := config.MappingProfile{Entity: entity, FuzzyHeaders: true}
+	for _, field := range fieldNames {
+		profile.Fields = append(profile.Fields, config.FieldMapping{
+			Field:    field,
+			Headers:  headersByField[field],
+			Required: required[field],
+			Default:  defaults[field],
+		})
+	}
+	return profile
+}
+
+// fieldBinding FieldMapping yang sudah divalidasi terhadap struct tujuan dan dipetakan ke kolom sheet
+type fieldBinding struct {
+	config.FieldMapping
+	index  int    // Index field di struct tujuan
+	column int    // Index kolom di sheet, -1 jika header tidak ditemukan
+	header string // Header di sheet (atau alias pertama jika kolom tidak ditemukan), untuk laporan validasi
+}
+
+// bindProfile memvalidasi profile terhadap tipe struct tujuan lalu mencari kolom untuk setiap field
+func bindProfile(profile config.MappingProfile, target reflect.Type, headers []string) ([]fieldBinding, error) {
+	bindings := make([]fieldBinding, 0, len(profile.Fields))
+	for _, mapping := range profile.Fields {
+		structField, ok := target.FieldByName(mapping.Field)
+		if !ok || len(structField.Index) != 1 {
+			return nil, fmt.Errorf("unknown field '%s' for %s", mapping.Field, target.Name())
+		}
+
+		fieldType := valueType(structField.Type)
+		if fieldType == "" {
+			return nil, fmt.Errorf("field '%s' has unsupported type %s", mapping.Field, structField.Type)
+		}
+		if mapping.Type == "" {
+			mapping.Type = fieldType
+		}
+		if !typeCompatible(mapping.Type, fieldType) {
+			return nil, fmt.Errorf("field '%s' cannot be mapped as type '%s'", mapping.Field, mapping.Type)
+		}
+
+		binding := fieldBinding{FieldMapping: mapping, index: structField.Index[0], column: -1, header: mapping.Field}
+		if len(mapping.Headers) > 0 {
+			binding.header = mapping.Headers[0]
+		}
+		matches := findColumns(headers, mapping.Headers, profile.FuzzyHeaders)
+		if len(matches) > 1 {
+			found := make([]string, len(matches))
+			for i, match := range matches {
+				found[i] = fmt.Sprintf("'%s' (column %d)", match.header, match.column)
+			}
+			return nil, fmt.Errorf("ambiguous columns for field %s: %s all match its headers", mapping.Field, strings.Join(found, ", "))
+		}
+		if len(matches) == 1 {
+			binding.column = matches[0].column
+			binding.header = matches[0].header
+			log.Printf("Mapped '%s' -> %s (column %d, alias '%s')", matches[0].header, mapping.Field, binding.column, matches[0].alias)
+		}
+		if binding.column == -1 && mapping.Required && mapping.Default == "" {
+			log.Printf("Warning: no column found for required field %s (headers: %s)", mapping.Field, strings.Join(mapping.Headers, ", "))
+		}
+
+		bindings = append(bindings, binding)
+	}
+
+	return bindings, nil
+}
+
+// valueType menentukan tipe nilai FieldMapping dari tipe field struct
+func valueType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return TypeString
+	case reflect.Float32, reflect.Float64:
+		return TypeNumber
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return TypeInteger
+	case reflect.Bool:
+		return TypeBool
+	default:
+		return ""
+	}
+}
+
+// typeCompatible memastikan tipe pada profile bisa disimpan ke field struct
+func typeCompatible(mappingType, fieldType string) bool {
+	switch fieldType {
+	case TypeString:
+		return mappingType == TypeString || mappingType == TypeNumber || mappingType == TypeInteger || mappingType == TypeBool
+	case TypeNumber:
+		return mappingType == TypeNumber || mappingType == TypeInteger
+	default:
+		return mappingType == fieldType
+	}
+}
+
+// applyRow mengisi field record (pointer ke struct) dari satu baris Excel dan mengembalikan
+// issue yang ditemukan. Baris harus ditolak jika ada issue dengan severity error;
+// nilai opsional yang tidak valid hanya menjadi warning dan dilewati.
+func applyRow(record reflect.Value, bindings []fieldBinding, row []string, rowNum int) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, binding := range bindings {
+		raw := ""
+		if binding.column != -1 && binding.column < len(row) {
+			raw = strings.TrimSpace(row[binding.column])
+		}
+		cell := raw
+		if isEmptyCell(raw) {
+			raw = binding.Default
+		}
+		if raw == "" {
+			if binding.Required {
+				issues = append(issues, ValidationIssue{
+					Row: rowNum, Cell: cellName(binding.column, rowNum), Column: binding.header, Field: binding.Field, Value: cell,
+					Rule: RuleRequired, Severity: SeverityError,
+					Message: fmt.Sprintf("%s is required", binding.Field),
+				})
+			}
+			continue
+		}
+
+		if err := setFieldValue(record.Field(binding.index), binding.Type, raw); err != nil {
+			issue := ValidationIssue{
+				Row: rowNum, Cell: cellName(binding.column, rowNum), Column: binding.header, Field: binding.Field, Value: raw,
+				Rule: RuleType, Severity: SeverityWarning,
+				Message: fmt.Sprintf("invalid %s '%s': expected %s", binding.Field, raw, binding.Type),
+			}
+			if binding.Required {
+				issue.Severity = SeverityError
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// isEmptyCell mengecek cell kosong. Teks NULL dari hasil export database juga dianggap kosong.
+func isEmptyCell(value string) bool {
+	return value == "" || strings.EqualFold(value, "null")
+}
+
+// setFieldValue mem-parse raw sesuai mappingType lalu menyimpannya ke field (boleh pointer)
+func setFieldValue(field reflect.Value, mappingType, raw string) error {
+	var value interface{}
+	switch mappingType {
+	case TypeString:
+		value = raw
+	case TypeNumber:
+		number, err := parseNumber(raw)
+		if err != nil {
+			return err
+		}
+		value = number
+	case TypeInteger:
+		number, err := parseNumber(raw)
+		if err != nil {
+			return err
+		}
+		if number != math.Trunc(number) {
+			return fmt.Errorf("expected an integer")
+		}
+		value = number
+	case TypeBool:
+		flag, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+		value = flag
+	}
+
+	target := field
+	if field.Kind() == reflect.Ptr {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			target.SetString(s)
+		} else {
+			target.SetString(raw)
+		}
+	case reflect.Float32, reflect.Float64:
+		target.SetFloat(value.(float64))
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		number := value.(float64)
+		if target.OverflowInt(int64(number)) {
+			return fmt.Errorf("value out of range")
+		}
+		target.SetInt(int64(number))
+	case reflect.Bool:
+		target.SetBool(value.(bool))
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+	}
+	return nil
+}
+
+// parseNumber membaca angka dari cell. NaN dan Infinity yang diterima strconv.ParseFloat
+// ditolak karena tidak bisa disimpan di kolom numeric.
+func parseNumber(raw string) (float64, error) {
+	number, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("expected a finite number")
+	}
+	return number, nil
+}
+
+// parseBool membaca nilai boolean dari Excel (ya/tidak, y/n, 1/0, true/false, t/f). "t" selalu
+// berarti true seperti di PostgreSQL, bukan singkatan "tidak".
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "ya", "y", "yes", "1", "true", "t":
+		return true, nil
+	case "tidak", "n", "no", "0", "false", "f":
+		return false, nil
+	default:
+		return false, fmt.Errorf("expected ya/tidak, y/n, 1/0, true/false or t/f")
+	}
+}
+
+// parseRecords membaca sheet sesuai profile dan membuat satu record per baris data. Pada
+// import multi-sheet setiap sheet dicocokkan dengan mapping-nya sendiri dan hasil validasinya
+// digabung. newRecord dipanggil dengan nama sheet dan nomor baris Excel (1-based) untuk membuat
+// record dengan nilai awal. Hanya baris di rentang data sheet yang dibaca (lihat sheetLayout).
+// Baris yang tidak valid tidak dikembalikan, tapi dicatat di ValidationResult.
+func parseRecords[T any](ctx context.Context, filename string, profile config.MappingProfile, newRecord func(sheet string, rowNum int) T) ([]T, *ValidationResult, error) {
+	src, err := openSource(filename, profile.Input)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+
+	sheets, err := selectSheets(src, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := newValidationResult()
+	var records []T
+	for _, sheet := range sheets {
+		layout, err := newSheetLayout(sheet.profile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sheet '%s': %w", sheet.name, err)
+		}
+		rows, err := src.getRows(sheet.name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading Excel rows: %v", err)
+		}
+		if len(rows) == 0 {
+			if len(sheets) == 1 {
+				return nil, nil, fmt.Errorf("Excel file is empty")
+			}
+			log.Printf("Warning: sheet '%s' is empty, skipping", sheet.name)
+			continue
+		}
+		if len(rows) < layout.headerRow {
+			return nil, nil, fmt.Errorf("header row %d not found, sheet '%s' only has %d rows", layout.headerRow, sheet.name, len(rows))
+		}
+		merges, err := layout.headerMerges(src, sheet.name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(sheets) > 1 {
+			log.Printf("Reading sheet '%s'", sheet.name)
+		}
+		headerLines := rows[layout.headerRow-1 : min(layout.lastHeaderRow(), len(rows))]
+		parser, err := newRecordParser(sheet, len(sheets) > 1, newRecord, layout, layout.headers(headerLines, merges), result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sheet '%s': %w", sheet.name, err)
+		}
+
+	rowLoop:
+		for i := layout.firstDataRow - 1; i < len(rows); i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, fmt.Errorf("parsing stopped at row %d: %w", i+1, err)
+			}
+			switch parser.action(rows[i], i+1) {
+			case rowStop:
+				break rowLoop
+			case rowSkip:
+				continue
+			}
+			if record, ok := parser.parse(rows[i], i+1); ok {
+				records = append(records, record)
+			}
+		}
+	}
+
+	return records, result, nil
+}
+
+// recordParser mengubah baris data satu sheet menjadi record T dan mengumpulkan hasil
+// validasinya ke ValidationResult yang dipakai bersama semua sheet
+type recordParser[T any] struct {
+	sheet     string
+	prefix    string // Awalan pesan log, berisi nama sheet pada import multi-sheet
+	layout    sheetLayout
+	bindings  []fieldBinding
+	newRecord func(sheet string, rowNum int) T
+	result    *ValidationResult
+}
+
+// newRecordParser mencocokkan header sheet dengan profile-nya dan mendaftarkan kolom setiap
+// field ke hasil validasi
+func newRecordParser[T any](sheet sheetSource, multiSheet bool, newRecord func(sheet string, rowNum int) T, layout sheetLayout, headers []string, result *ValidationResult) (*recordParser[T], error) {
+	bindings, err := bindProfile(sheet.profile, reflect.TypeOf(newRecord(sheet.name, 0)), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]fieldColumn, len(bindings))
+	for _, binding := range bindings {
+		columns[binding.Field] = fieldColumn{index: binding.column, header: binding.header, fixed: binding.Default != ""}
+	}
+	result.columns[sheet.name] = columns
+
+	parser := &recordParser[T]{sheet: sheet.name, layout: layout, bindings: bindings, newRecord: newRecord, result: result}
+	if multiSheet {
+		parser.prefix = "[" + sheet.name + "] "
+	}
+	return parser, nil
+}
+
+// Tindakan untuk satu baris di rentang data, hasil recordParser.action
+const (
+	rowParse = iota // Baris dibaca dan divalidasi
+	rowSkip         // Baris dilewati, misalnya footer TOTAL atau baris kosong
+	rowStop         // Baris ini dan seterusnya tidak dibaca lagi
+)
+
+// action menentukan apakah baris data dibaca, dilewati (skip_prefixes, atau semua kolom yang
+// di-mapping kosong) atau menjadi akhir data sheet (setelah last_data_row, atau baris kosong
+// pertama dengan stop_at_blank)
+func (p *recordParser[T]) action(row []string, rowNum int) int {
+	if p.layout.afterData(rowNum) {
+		return rowStop
+	}
+	if isBlankRow(row) && p.layout.stopAtBlank {
+		log.Printf("%sRow %d: blank row, stopping", p.prefix, rowNum)
+		return rowStop
+	}
+	if prefix, ok := p.layout.skipPrefix(row); ok {
+		log.Printf("%sRow %d: starts with '%s', skipping row", p.prefix, rowNum, prefix)
+		return rowSkip
+	}
+	if p.mappedBlank(row) {
+		p.skipBlank(row, rowNum)
+		return rowSkip
+	}
+	return rowParse
+}
+
+// skipBlank mencatat baris yang dilewati karena semua kolom yang di-mapping kosong. Jika kolom
+// lain yang tidak di-mapping berisi (misalnya catatan), baris dicatat sebagai warning agar
+// terlihat di laporan validasi dan file error; baris yang benar-benar kosong dilewati diam-diam.
+func (p *recordParser[T]) skipBlank(row []string, rowNum int) {
+	var values []string
+	for _, value := range row {
+		if value = strings.TrimSpace(value); !isEmptyCell(value) {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	issue := ValidationIssue{
+		Sheet:    p.sheet,
+		Row:      rowNum,
+		Value:    strings.Join(values, ", "),
+		Rule:     RuleBlank,
+		Message:  "all mapped columns are empty, row skipped",
+		Severity: SeverityWarning,
+	}
+	log.Printf("%sRow %d: Warning: %s", p.prefix, rowNum, issue.Message)
+	p.result.Add(issue)
+}
+
+// mappedBlank memeriksa apakah semua cell yang di-mapping ke field kosong, sehingga baris
+// kosong di tengah sheet tidak ditolak sebagai baris tanpa field wajib. Jika tidak ada kolom
+// yang ditemukan sama sekali, baris tetap dibaca agar field wajib yang hilang dilaporkan.
+// Baris yang hanya berisi kolom yang tidak di-mapping dicatat sebagai warning oleh skipBlank.
+func (p *recordParser[T]) mappedBlank(row []string) bool {
+	mapped := false
+	for _, binding := range p.bindings {
+		if binding.column == -1 {
+			continue
+		}
+		mapped = true
+		if binding.column < len(row) && !isEmptyCell(strings.TrimSpace(row[binding.column])) {
+			return false
+		}
+	}
+	return mapped
+}
+
+// parse membaca satu baris data. ok bernilai false jika baris ditolak validasi.
+func (p *recordParser[T]) parse(row []string, rowNum int) (record T, ok bool) {
+	p.result.RowsRead++
+
+	record = p.newRecord(p.sheet, rowNum)
+	issues := applyRow(reflect.ValueOf(&record).Elem(), p.bindings, row, rowNum)
+
+	rejected := false
+	for i, issue := range issues {
+		issues[i].Sheet = p.sheet
+		if issue.Severity == SeverityError {
+			rejected = true
+			log.Printf("%sRow %d: %s, skipping", p.prefix, rowNum, issue.Message)
+		} else {
+			log.Printf("%sRow %d: Warning: %s, skipping value", p.prefix, rowNum, issue.Message)
+		}
+	}
+	if rejected {
+		p.result.RowsRejected++
+	}
+	p.result.Issues = append(p.result.Issues, issues...)
+
+	return record, !rejected
+}
@@ -0,0 +1,134 @@
+package excel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSetFieldValueNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		mappingType string
+		raw         string
+		want        float64
+		wantErr     bool
+	}{
+		{"decimal", TypeNumber, "1234.567", 1234.567, false},
+		{"negative", TypeNumber, "-2.5", -2.5, false},
+		{"exponent", TypeNumber, "1e3", 1000, false},
+		{"integer", TypeInteger, "12", 12, false},
+		{"fraction as integer", TypeInteger, "2.5", 0, true},
+		{"text", TypeNumber, "abc", 0, true},
+		{"NaN", TypeNumber, "NaN", 0, true},
+		{"Inf", TypeNumber, "Inf", 0, true},
+		{"negative infinity", TypeNumber, "-Infinity", 0, true},
+		{"integer infinity", TypeInteger, "+inf", 0, true},
+		{"overflow", TypeNumber, "1e400", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var number *float64
+			err := setFieldValue(reflect.ValueOf(&number).Elem(), tt.mappingType, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setFieldValue(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if number != nil {
+					t.Errorf("setFieldValue(%q) set %v on error", tt.raw, *number)
+				}
+				return
+			}
+			if number == nil || *number != tt.want {
+				t.Errorf("setFieldValue(%q) = %v, want %v", tt.raw, number, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSkipsRowsWithEmptyMappedColumns(t *testing.T) {
+	path := writeTestWorkbook(t, testSheet{"Sheet1", [][]interface{}{
+		{"Kode Barang", "Nama Barang", "HargaBeli", "Catatan"},
+		{"1001", "GULA 1KG", 14000, ""},
+		{},
+		{"", "", "", "dicek ulang"},
+		{"NULL", "null", "", ""},
+		{"1002", "", 15000, ""},
+		{"1003", "TEH 250G", 9000, ""},
+	}})
+
+	tests := []struct {
+		name        string
+		stopAtBlank bool
+		items       int
+		rejected    int
+		skipped     []int // Baris dengan warning RuleBlank
+	}{
+		{"skip blank rows", false, 2, 1, []int{4}},
+		{"stop at blank", true, 1, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := DefaultItemProfile()
+			profile.StopAtBlank = tt.stopAtBlank
+			items, validation, err := ParseExcelToMItems(context.Background(), path, profile)
+			if err != nil {
+				t.Fatalf("ParseExcelToMItems: %v", err)
+			}
+			if len(items) != tt.items || validation.RowsRejected != tt.rejected {
+				t.Errorf("items=%d rejected=%d, want items=%d rejected=%d (issues %+v)",
+					len(items), validation.RowsRejected, tt.items, tt.rejected, validation.Issues)
+			}
+
+			var skipped []int
+			for _, issue := range validation.Issues {
+				if issue.Rule == RuleBlank {
+					if issue.Severity != SeverityWarning || issue.Value != "dicek ulang" {
+						t.Errorf("blank issue %+v, want warning with value 'dicek ulang'", issue)
+					}
+					skipped = append(skipped, issue.Row)
+				}
+			}
+			if !reflect.DeepEqual(skipped, tt.skipped) || validation.RowsSkipped() != len(tt.skipped) {
+				t.Errorf("skipped rows %v (RowsSkipped %d), want %v", skipped, validation.RowsSkipped(), tt.skipped)
+			}
+		})
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{"ya", true, false},
+		{"Y", true, false},
+		{"yes", true, false},
+		{"1", true, false},
+		{"TRUE", true, false},
+		{"t", true, false},
+		{" T ", true, false},
+		{"tidak", false, false},
+		{"n", false, false},
+		{"no", false, false},
+		{"0", false, false},
+		{"false", false, false},
+		{"f", false, false},
+		{"F", false, false},
+		{"", false, true},
+		{"ok", false, true},
+		{"2", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseBool(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBool(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBool(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
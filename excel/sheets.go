@@ -0,0 +1,117 @@
+package excel
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"excel-seeder/config"
+)
+
+// sheetSource satu sheet yang dibaca beserta mapping efektifnya
+type sheetSource struct {
+	name    string
+	profile config.MappingProfile // Profile dengan header_row, fields dan nilai tetap sheet ini
+}
+
+// selectSheets menentukan sheet yang dibaca sesuai profile: daftar Sheets, semua sheet
+// yang terlihat (AllSheets), atau satu Sheet. Header_row, header_rows, rentang baris data dan
+// fields sheet menimpa milik profile; Values dan SheetField ditambahkan sebagai field dengan
+// nilai tetap.
+func selectSheets(src source, profile config.MappingProfile) ([]sheetSource, error) {
+	var mappings []config.SheetMapping
+	switch {
+	case len(profile.Sheets) > 0:
+		mappings = profile.Sheets
+	case profile.AllSheets:
+		for _, name := range src.sheetList() {
+			if !src.sheetVisible(name) {
+				log.Printf("Skipping hidden sheet '%s'", name)
+				continue
+			}
+			mappings = append(mappings, config.SheetMapping{Sheet: name})
+		}
+	default:
+		mappings = []config.SheetMapping{{Sheet: profile.Sheet}}
+	}
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("workbook has no visible sheets")
+	}
+
+	sheets := make([]sheetSource, 0, len(mappings))
+	seen := make(map[string]bool)
+	for _, mapping := range mappings {
+		name, err := src.resolveSheet(mapping.Sheet)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("sheet '%s' is selected more than once", name)
+		}
+		seen[name] = true
+
+		sheetProfile := profile
+		sheetProfile.Sheet = name
+		sheetProfile.Sheets = nil
+		sheetProfile.AllSheets = false
+		if mapping.HeaderRow != 0 {
+			sheetProfile.HeaderRow = mapping.HeaderRow
+		}
+		if mapping.HeaderRows != 0 {
+			sheetProfile.HeaderRows = mapping.HeaderRows
+		}
+		if mapping.FirstDataRow != 0 {
+			sheetProfile.FirstDataRow = mapping.FirstDataRow
+		}
+		if mapping.LastDataRow != 0 {
+			sheetProfile.LastDataRow = mapping.LastDataRow
+		}
+		if len(mapping.Fields) > 0 {
+			sheetProfile.Fields = mapping.Fields
+		}
+
+		values := make(map[string]string, len(mapping.Values)+1)
+		for field, value := range mapping.Values {
+			values[field] = value
+		}
+		if profile.SheetField != "" {
+			if _, ok := values[profile.SheetField]; !ok {
+				values[profile.SheetField] = name
+			}
+		}
+		sheetProfile.Fields = withFixedValues(sheetProfile.Fields, values)
+
+		sheets = append(sheets, sheetSource{name: name, profile: sheetProfile})
+	}
+	return sheets, nil
+}
+
+// withFixedValues mengembalikan salinan fields dengan field pada values diisi nilai tetap.
+// Field yang sudah di-mapping tidak lagi dibaca dari kolom; field baru ditambahkan.
+func withFixedValues(fields []config.FieldMapping, values map[string]string) []config.FieldMapping {
+	if len(values) == 0 {
+		return fields
+	}
+	names := make([]string, 0, len(values))
+	for field := range values {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	result := append([]config.FieldMapping(nil), fields...)
+	for _, field := range names {
+		fixed := config.FieldMapping{Field: field, Default: values[field]}
+		replaced := false
+		for i := range result {
+			if result[i].Field == field {
+				fixed.Type, fixed.Required = result[i].Type, result[i].Required
+				result[i] = fixed
+				replaced = true
+			}
+		}
+		if !replaced {
+			result = append(result, fixed)
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package excel
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"excel-seeder/config"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Format file input yang didukung
+const (
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+)
+
+// source file input yang dibaca per sheet oleh parser, streaming dan file error. Workbook
+// Excel dibaca dengan excelize; file CSV/TSV dibaca sebagai workbook satu sheet tanpa cell merge.
+type source interface {
+	sheetList() []string
+	sheetVisible(sheet string) bool
+	// resolveSheet mencari sheet berdasarkan nama atau nomor urut (1-based); kosong berarti sheet pertama
+	resolveSheet(sheet string) (string, error)
+	getRows(sheet string) ([][]string, error)
+	rows(sheet string) (rowIterator, error)
+	mergeCells(sheet string) ([]excelize.MergeCell, error)
+	Close() error
+}
+
+// rowIterator iterator baris satu sheet, dengan perilaku yang sama seperti excelize.Rows
+type rowIterator interface {
+	Next() bool
+	Columns() ([]string, error)
+	Error() error
+	Close() error
+}
+
+// inputFormat menentukan format file input: opsi format jika diisi, selain itu dari ekstensi
+// file (.csv dan .txt dibaca sebagai CSV, .tsv dan .tab sebagai TSV, lainnya sebagai Excel)
+func inputFormat(filename string, opts config.InputOptions) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(opts.Format)); format {
+	case FormatXLSX, FormatCSV, FormatTSV:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported input format '%s', use xlsx, csv or tsv", opts.Format)
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".tsv", ".tab":
+		return FormatTSV, nil
+	default:
+		return FormatXLSX, nil
+	}
+}
+
+// CheckInputOptions memeriksa opsi input yang diisi tanpa membuka file, sehingga nilai flag
+// yang salah bisa dilaporkan sebelum pekerjaan dimulai
+func CheckInputOptions(opts config.InputOptions) error {
+	if _, err := inputFormat("", opts); err != nil {
+		return err
+	}
+	if opts.Encoding != "" {
+		if _, _, err := csvEncoding(nil, opts.Encoding); err != nil {
+			return err
+		}
+	}
+	quote, err := csvQuote("", opts.Quote)
+	if err != nil {
+		return err
+	}
+	_, err = csvDelimiter("", FormatCSV, opts.Delimiter, quote)
+	return err
+}
+
+// openSource membuka file input sesuai formatnya
+func openSource(filename string, opts config.InputOptions) (source, error) {
+	format, err := inputFormat(filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatCSV || format == FormatTSV {
+		return openCSV(filename, format, opts)
+	}
+
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening Excel file: %v", err)
+	}
+	return excelSource{f}, nil
+}
+
+// excelSource workbook Excel yang dibaca dengan excelize
+type excelSource struct {
+	f *excelize.File
+}
+
+func (s excelSource) sheetList() []string {
+	return s.f.GetSheetList()
+}
+
+func (s excelSource) sheetVisible(sheet string) bool {
+	visible, err := s.f.GetSheetVisible(sheet)
+	return err != nil || visible
+}
+
+// resolveSheet mencari sheet berdasarkan nama, atau nomor urut (1-based) jika tidak ada sheet
+// dengan nama tersebut. Kosong berarti sheet pertama.
+func (s excelSource) resolveSheet(sheet string) (string, error) {
+	list := s.f.GetSheetList()
+	if sheet == "" {
+		if len(list) == 0 {
+			return "", fmt.Errorf("workbook has no sheets")
+		}
+		return list[0], nil
+	}
+	if index, err := s.f.GetSheetIndex(sheet); err == nil && index >= 0 {
+		return sheet, nil
+	}
+	if number, err := strconv.Atoi(sheet); err == nil && number >= 1 && number <= len(list) {
+		return list[number-1], nil
+	}
+	return "", fmt.Errorf("sheet '%s' not found, workbook has: %s", sheet, strings.Join(list, ", "))
+}
+
+// rawCells membaca nilai cell apa adanya, tanpa format angka cell. Angka di kolom harga yang
+// diformat 0.00 tetap terbaca dengan seluruh desimalnya, bukan teks yang sudah dibulatkan.
+var rawCells = excelize.Options{RawCellValue: true}
+
+func (s excelSource) getRows(sheet string) ([][]string, error) {
+	return s.f.GetRows(sheet, rawCells)
+}
+
+func (s excelSource) rows(sheet string) (rowIterator, error) {
+	rows, err := s.f.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	return excelRows{rows}, nil
+}
+
+func (s excelSource) mergeCells(sheet string) ([]excelize.MergeCell, error) {
+	return s.f.GetMergeCells(sheet)
+}
+
+func (s excelSource) Close() error {
+	return s.f.Close()
+}
+
+// excelRows menyesuaikan excelize.Rows dengan rowIterator
+type excelRows struct {
+	*excelize.Rows
+}
+
+func (r excelRows) Columns() ([]string, error) {
+	return r.Rows.Columns(rawCells)
+}
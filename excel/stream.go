@@ -0,0 +1,204 @@
+package excel
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"excel-seeder/config"
+	"excel-seeder/models"
+)
+
+// StreamExcelToMItems membaca sheet barang baris demi baris dan memanggil flush setiap
+// batchSize item valid terkumpul, sehingga workbook besar tidak perlu dimuat seluruhnya
+// ke memory. flush boleh menambahkan issue ke ValidationResult yang diberikan.
+func StreamExcelToMItems(ctx context.Context, filename string, profile config.MappingProfile, batchSize int, flush func([]models.MItem, *ValidationResult) error) (*ValidationResult, error) {
+	return streamRecords(ctx, filename, profile, newItemRecord, batchSize, flush)
+}
+
+// StreamExcelToMSupps sama seperti StreamExcelToMItems untuk sheet supplier
+func StreamExcelToMSupps(ctx context.Context, filename string, profile config.MappingProfile, batchSize int, flush func([]models.MSupp, *ValidationResult) error) (*ValidationResult, error) {
+	return streamRecords(ctx, filename, profile, newSupplierRecord, batchSize, flush)
+}
+
+// streamRecords versi streaming dari parseRecords memakai iterator baris source. Sheet
+// dibaca berurutan dan batch bisa berisi baris dari beberapa sheet. Jika terjadi error
+// setelah ada baris yang dibaca, hasil validasi sampai titik itu tetap dikembalikan.
+func streamRecords[T any](ctx context.Context, filename string, profile config.MappingProfile, newRecord func(sheet string, rowNum int) T, batchSize int, flush func([]T, *ValidationResult) error) (*ValidationResult, error) {
+	if batchSize < 1 {
+		return nil, fmt.Errorf("invalid stream batch size %d", batchSize)
+	}
+
+	src, err := openSource(filename, profile.Input)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	sheets, err := selectSheets(src, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newValidationResult()
+	failed := func(err error) (*ValidationResult, error) {
+		if result.RowsRead == 0 {
+			return nil, err
+		}
+		return result, err
+	}
+
+	batch := make([]T, 0, batchSize)
+	for _, sheet := range sheets {
+		if len(sheets) > 1 {
+			log.Printf("Reading sheet '%s'", sheet.name)
+		}
+		var lastRow int
+		batch, lastRow, err = streamSheet(ctx, src, sheet, len(sheets) > 1, newRecord, result, batch, batchSize, flush)
+		if err != nil {
+			return failed(err)
+		}
+		if lastRow == 0 {
+			if len(sheets) == 1 {
+				return nil, fmt.Errorf("Excel file is empty")
+			}
+			log.Printf("Warning: sheet '%s' is empty, skipping", sheet.name)
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := flush(batch, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// streamSheet membaca satu sheet baris demi baris, menambahkan record valid ke batch dan
+// memanggil flush setiap batch penuh. Mengembalikan sisa batch dan nomor baris terakhir yang
+// tidak kosong (0 jika sheet kosong). Penomoran baris dan perlakuan baris kosong sama dengan
+// GetRows: baris kosong di tengah data dilewati dengan warning, baris kosong di akhir sheet diabaikan.
+func streamSheet[T any](ctx context.Context, src source, sheet sheetSource, multiSheet bool, newRecord func(sheet string, rowNum int) T, result *ValidationResult, batch []T, batchSize int, flush func([]T, *ValidationResult) error) ([]T, int, error) {
+	layout, err := newSheetLayout(sheet.profile)
+	if err != nil {
+		return batch, 0, fmt.Errorf("sheet '%s': %w", sheet.name, err)
+	}
+
+	rows, err := src.rows(sheet.name)
+	if err != nil {
+		return batch, 0, fmt.Errorf("error reading Excel rows: %v", err)
+	}
+	defer rows.Close()
+
+	var (
+		parser      *recordParser[T]
+		headerLines = make([][]string, layout.headerRows)
+		rowNum      = 0
+		lastRow     = 0                   // Baris terakhir yang tidak kosong
+		nextRow     = layout.firstDataRow // Baris data berikutnya yang belum diproses
+	)
+	newParser := func() error {
+		merges, err := layout.headerMerges(src, sheet.name)
+		if err != nil {
+			return err
+		}
+		parser, err = newRecordParser(sheet, multiSheet, newRecord, layout, layout.headers(headerLines, merges), result)
+		return err
+	}
+	// process membaca satu baris data; stop bernilai true jika data sheet sudah berakhir
+	process := func(row []string, rowNum int) (stop bool, err error) {
+		switch parser.action(row, rowNum) {
+		case rowStop:
+			return true, nil
+		case rowSkip:
+			return false, nil
+		}
+		record, ok := parser.parse(row, rowNum)
+		if !ok {
+			return false, nil
+		}
+		batch = append(batch, record)
+		if len(batch) >= batchSize {
+			if err := flush(batch, result); err != nil {
+				return false, err
+			}
+			batch = make([]T, 0, batchSize)
+		}
+		return false, nil
+	}
+
+	for rows.Next() {
+		rowNum++
+		if err := ctx.Err(); err != nil {
+			return batch, lastRow, fmt.Errorf("streaming stopped at row %d: %w", rowNum, err)
+		}
+		row, err := rows.Columns()
+		if err != nil {
+			return batch, lastRow, fmt.Errorf("error reading Excel row %d: %v", rowNum, err)
+		}
+
+		if rowNum < layout.firstDataRow {
+			if layout.isHeaderRow(rowNum) {
+				headerLines[rowNum-layout.headerRow] = row
+			}
+			if len(row) > 0 {
+				lastRow = rowNum
+			}
+			if rowNum == layout.lastHeaderRow() && lastRow >= layout.headerRow {
+				if err := newParser(); err != nil {
+					return batch, lastRow, err
+				}
+			}
+			continue
+		}
+
+		if len(row) == 0 {
+			// Baris kosong diproses saat baris berikutnya yang terisi ditemukan, kecuali
+			// baris ini sudah mengakhiri data sheet
+			if !layout.stopAtBlank && !layout.afterData(rowNum) {
+				continue
+			}
+		} else {
+			lastRow = rowNum
+		}
+		if parser == nil {
+			// Baris header kosong: field wajib akan dilaporkan tidak ditemukan
+			if err := newParser(); err != nil {
+				return batch, lastRow, err
+			}
+		}
+
+		// Baris kosong di antara data diproses seperti pada parseRecords
+		stop := false
+		for ; nextRow < rowNum && !stop; nextRow++ {
+			if stop, err = process(nil, nextRow); err != nil {
+				return batch, lastRow, err
+			}
+		}
+		if !stop {
+			nextRow = rowNum + 1
+			stop, err = process(row, rowNum)
+			if err != nil {
+				return batch, lastRow, err
+			}
+		}
+		if stop {
+			break
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return batch, lastRow, fmt.Errorf("error reading Excel rows: %v", err)
+	}
+
+	if lastRow > 0 && lastRow < layout.headerRow {
+		return batch, lastRow, fmt.Errorf("header row %d not found, sheet '%s' only has %d rows", layout.headerRow, sheet.name, lastRow)
+	}
+	if lastRow > 0 && parser == nil {
+		// Blok header melewati baris terakhir sheet, mapping tetap divalidasi
+		if err := newParser(); err != nil {
+			return batch, lastRow, err
+		}
+	}
+	return batch, lastRow, nil
+}
@@ -0,0 +1,53 @@
+package excel
+
+import (
+	"context"
+	"time"
+
+	"excel-seeder/config"
+	"excel-seeder/models"
+	"excel-seeder/utils"
+)
+
+// SupplierHeaderMapping mapping header sheet supplier ke field struct MSupp (case-insensitive)
+var SupplierHeaderMapping = map[string]string{
+	"kode":          "Code",
+	"kode supplier": "Code",
+	"nama":          "Name",
+	"nama supplier": "Name",
+	"tipe":          "Type",
+	"asal":          "Origin",
+	"nib":           "NIB",
+	"npwp":          "NPWP",
+	"ppn":           "FlagPPN",
+	"alamat":        "Addr",
+	"kode pos":      "PostCode",
+	"telp":          "Phone1",
+	"telp 2":        "Phone2",
+	"kontak":        "CP1",
+	"telp kontak":   "CP1Phone",
+	"kontak 2":      "CP2",
+	"telp kontak 2": "CP2Phone",
+	"keterangan":    "Desc",
+}
+
+// SupplierRequiredFields daftar field supplier yang wajib diisi
+var SupplierRequiredFields = map[string]bool{
+	"Name": true,
+}
+
+// ParseExcelToMSupps membaca sheet supplier berdasarkan mapping profile beserta hasil validasinya
+func ParseExcelToMSupps(ctx context.Context, filename string, profile config.MappingProfile) ([]models.MSupp, *ValidationResult, error) {
+	return parseRecords(ctx, filename, profile, newSupplierRecord)
+}
+
+// newSupplierRecord membuat MSupp dengan nilai awal untuk baris rowNum pada sheet
+func newSupplierRecord(sheet string, rowNum int) models.MSupp {
+	return models.MSupp{
+		SourceSheet: sheet,
+		SourceRow:   rowNum,
+		IsActive:    true,
+		CreatedAt:   utils.TimePtr(time.Now()),
+		UpdatedAt:   utils.TimePtr(time.Now()),
+	}
+}
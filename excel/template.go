@@ -0,0 +1,291 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"excel-seeder/config"
+	"excel-seeder/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// templateRows jumlah baris data yang diberi dropdown dan format angka pada template
+const templateRows = 10000
+
+// DefaultTemplateUnits pilihan satuan pada dropdown kolom Unit di template
+var DefaultTemplateUnits = []string{"PCS", "PAK", "BOX", "DUS", "KRT", "LUSIN", "KG", "GR", "LTR", "ML", "BTL", "SAK"}
+
+// templateExamples contoh nilai per field untuk baris contoh di template
+var templateExamples = map[string]interface{}{
+	// Item
+	"Barcode":             "8998866200301",
+	"Code":                "BRG0001",
+	"ItemName":            "INDOMIE GORENG 85G",
+	"ItemNameLong":        "INDOMIE MI INSTAN GORENG 85 GRAM",
+	"Unit":                "PCS",
+	"Mnfct":               "INDOFOOD",
+	"PriceBase":           2800,
+	"DefaultPriceSale":    3100,
+	"WholesaleMinQty":     40,
+	"WholesaleUnitPrice":  3000,
+	"Wholesale2MinQty":    80,
+	"Wholesale2UnitPrice": 2950,
+	"Wholesale3MinQty":    120,
+	"Wholesale3UnitPrice": 2900,
+	"SupplierName":        "SUP001",
+	"Weight":              85,
+
+	// Supplier
+	"Name":     "PT SUMBER MAKMUR",
+	"Origin":   "SURABAYA",
+	"Type":     "DISTRIBUTOR",
+	"NPWP":     "01.234.567.8-901.000",
+	"Addr":     "JL. RAYA DARMO NO. 1",
+	"PostCode": "60241",
+	"Phone1":   "031-5678901",
+	"CP1":      "BUDI",
+	"CP1Phone": "081234567890",
+	"Desc":     "SUPPLIER MI INSTAN",
+}
+
+// TemplateOptions mengatur isi template import
+type TemplateOptions struct {
+	Units []string // Pilihan dropdown kolom Unit; kosong berarti DefaultTemplateUnits
+}
+
+// WriteMItemTemplate menulis template import barang sesuai mapping profile
+func WriteMItemTemplate(profile config.MappingProfile, outputPath string, opts TemplateOptions) error {
+	return writeTemplate(reflect.TypeOf(models.MItem{}), profile, outputPath, opts)
+}
+
+// WriteMSuppTemplate menulis template import supplier sesuai mapping profile
+func WriteMSuppTemplate(profile config.MappingProfile, outputPath string, opts TemplateOptions) error {
+	return writeTemplate(reflect.TypeOf(models.MSupp{}), profile, outputPath, opts)
+}
+
+// TemplateHeaderRow nomor baris header pada template. Baris catatan selalu berada tepat di
+// atas header, sehingga header minimal berada di baris 2.
+func TemplateHeaderRow(profile config.MappingProfile) (int, error) {
+	headerRow, err := headerRowNumber(profile)
+	if err != nil {
+		return 0, err
+	}
+	if headerRow < 2 {
+		return 2, nil
+	}
+	return headerRow, nil
+}
+
+// writeTemplate menulis workbook kosong dengan header persis dari profile (alias pertama),
+// baris catatan field wajib di atas header, satu baris contoh, dropdown untuk kolom bool dan
+// satuan, serta format angka untuk kolom harga dan jumlah
+func writeTemplate(target reflect.Type, profile config.MappingProfile, outputPath string, opts TemplateOptions) error {
+	headerRow, err := TemplateHeaderRow(profile)
+	if err != nil {
+		return err
+	}
+	units := opts.Units
+	if len(units) == 0 {
+		units = DefaultTemplateUnits
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	sheetName := profile.Sheet
+	if sheetName == "" {
+		sheetName = f.GetSheetName(0)
+	}
+	if err := f.SetSheetName(f.GetSheetName(0), sheetName); err != nil {
+		return err
+	}
+
+	styles, err := newTemplateStyles(f)
+	if err != nil {
+		return err
+	}
+
+	for i, mapping := range profile.Fields {
+		structField, ok := target.FieldByName(mapping.Field)
+		if !ok || len(structField.Index) != 1 {
+			return fmt.Errorf("unknown field '%s' for %s", mapping.Field, target.Name())
+		}
+		fieldType := mapping.Type
+		if fieldType == "" {
+			fieldType = valueType(structField.Type)
+		}
+
+		column, _ := excelize.ColumnNumberToName(i + 1)
+		header := mapping.Field
+		if len(mapping.Headers) > 0 {
+			header = mapping.Headers[0]
+		}
+		firstData := fmt.Sprintf("%s%d", column, headerRow+1)
+		lastData := fmt.Sprintf("%s%d", column, headerRow+templateRows)
+
+		// Format angka untuk seluruh baris data
+		if style, ok := styles.numbers[templateNumberKind(mapping.Field, fieldType)]; ok {
+			if err := f.SetCellStyle(sheetName, firstData, lastData, style); err != nil {
+				return err
+			}
+		}
+
+		// Catatan, header dan contoh
+		noteStyle := styles.optional
+		if mapping.Required {
+			noteStyle = styles.required
+		}
+		cells := []struct {
+			row   int
+			value interface{}
+			style int
+		}{
+			{headerRow - 1, templateNote(mapping, fieldType), noteStyle},
+			{headerRow, header, styles.header},
+			{headerRow + 1, templateExample(mapping.Field, fieldType), 0},
+		}
+		for _, c := range cells {
+			cell := fmt.Sprintf("%s%d", column, c.row)
+			if err := f.SetCellValue(sheetName, cell, c.value); err != nil {
+				return err
+			}
+			if c.style != 0 {
+				if err := f.SetCellStyle(sheetName, cell, cell, c.style); err != nil {
+					return err
+				}
+			}
+		}
+
+		width := float64(len(header) + 4)
+		if width < 14 {
+			width = 14
+		}
+		if err := f.SetColWidth(sheetName, column, column, width); err != nil {
+			return err
+		}
+
+		// Dropdown untuk kolom bool dan satuan
+		var choices []string
+		switch {
+		case fieldType == TypeBool:
+			choices = []string{"ya", "tidak"}
+		case mapping.Field == "Unit":
+			choices = units
+		}
+		if len(choices) > 0 {
+			validation := excelize.NewDataValidation(true)
+			validation.SetSqref(firstData + ":" + lastData)
+			if err := validation.SetDropList(choices); err != nil {
+				return fmt.Errorf("error adding dropdown for %s: %v", mapping.Field, err)
+			}
+			validation.SetError(excelize.DataValidationErrorStyleStop, header, "Pilih salah satu: "+strings.Join(choices, ", "))
+			if err := f.AddDataValidation(sheetName, validation); err != nil {
+				return fmt.Errorf("error adding dropdown for %s: %v", mapping.Field, err)
+			}
+		}
+	}
+
+	// Bekukan baris catatan dan header agar tetap terlihat saat scroll
+	topLeft := fmt.Sprintf("A%d", headerRow+1)
+	if err := f.SetPanes(sheetName, &excelize.Panes{Freeze: true, YSplit: headerRow, TopLeftCell: topLeft, ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	if err := f.SaveAs(outputPath); err != nil {
+		return fmt.Errorf("error saving template: %v", err)
+	}
+	return nil
+}
+
+// templateStyles style yang dipakai template
+type templateStyles struct {
+	header   int
+	required int
+	optional int
+	numbers  map[string]int // Jenis angka (price, integer, number) -> style
+}
+
+// newTemplateStyles mendaftarkan style template ke workbook
+func newTemplateStyles(f *excelize.File) (templateStyles, error) {
+	var styles templateStyles
+	var err error
+	styles.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+	})
+	if err != nil {
+		return styles, err
+	}
+	styles.required, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Color: "C00000"}})
+	if err != nil {
+		return styles, err
+	}
+	styles.optional, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Italic: true, Color: "808080"}})
+	if err != nil {
+		return styles, err
+	}
+
+	// 3 = #,##0, 4 = #,##0.00 (format angka bawaan Excel). Format hanya mengubah tampilan,
+	// parser membaca nilai cell apa adanya.
+	styles.numbers = make(map[string]int)
+	for kind, numFmt := range map[string]int{"price": 4, TypeInteger: 3, TypeNumber: 4} {
+		styles.numbers[kind], err = f.NewStyle(&excelize.Style{NumFmt: numFmt})
+		if err != nil {
+			return styles, err
+		}
+	}
+	return styles, nil
+}
+
+// templateNumberKind menentukan format angka kolom: price untuk kolom harga,
+// atau tipe nilainya untuk kolom angka lain. Kolom teks dan bool tidak diformat.
+func templateNumberKind(field, fieldType string) string {
+	if fieldType != TypeNumber && fieldType != TypeInteger {
+		return ""
+	}
+	if strings.Contains(field, "Price") {
+		return "price"
+	}
+	return fieldType
+}
+
+// templateNote teks catatan di atas header: wajib/opsional beserta jenis isian
+func templateNote(mapping config.FieldMapping, fieldType string) string {
+	note := "Opsional"
+	if mapping.Required {
+		note = "Wajib"
+		if mapping.Default != "" {
+			note = "Wajib (kosong = " + mapping.Default + ")"
+		}
+	}
+
+	switch fieldType {
+	case TypeNumber:
+		note += ", angka"
+	case TypeInteger:
+		note += ", bilangan bulat"
+	case TypeBool:
+		note += ", ya/tidak"
+	default:
+		note += ", teks"
+	}
+	return note
+}
+
+// templateExample contoh nilai untuk field, atau nilai umum sesuai tipenya
+func templateExample(field, fieldType string) interface{} {
+	if example, ok := templateExamples[field]; ok {
+		return example
+	}
+	switch fieldType {
+	case TypeNumber:
+		return 0
+	case TypeInteger:
+		return 1
+	case TypeBool:
+		return "ya"
+	default:
+		return ""
+	}
+}
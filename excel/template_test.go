@@ -0,0 +1,78 @@
+package excel
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TestTemplateKeepsNumberPrecision memastikan kolom angka template memakai format angka dan
+// angka yang diisi tetap terbaca apa adanya, tanpa dibulatkan oleh format tersebut
+func TestTemplateKeepsNumberPrecision(t *testing.T) {
+	profile := DefaultItemProfile()
+	path := filepath.Join(t.TempDir(), "template.xlsx")
+	if err := WriteMItemTemplate(profile, path, TemplateOptions{}); err != nil {
+		t.Fatalf("WriteMItemTemplate: %v", err)
+	}
+	headerRow, err := TemplateHeaderRow(profile)
+	if err != nil {
+		t.Fatalf("TemplateHeaderRow: %v", err)
+	}
+
+	values := map[string]float64{
+		"PriceBase":          1234.567,
+		"WholesaleMinQty":    2.5,
+		"WholesaleUnitPrice": 0.125,
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	sheet := f.GetSheetName(0)
+	for i, mapping := range profile.Fields {
+		value, ok := values[mapping.Field]
+		if !ok {
+			continue
+		}
+		cell, _ := excelize.CoordinatesToCellName(i+1, headerRow+1)
+		// Kolom angka tetap diberi format angka, yang hanya mengubah tampilan
+		styleID, err := f.GetCellStyle(sheet, cell)
+		if err != nil {
+			t.Fatalf("GetCellStyle: %v", err)
+		}
+		if style, err := f.GetStyle(styleID); err != nil || style.NumFmt == 0 {
+			t.Errorf("%s has no number format (style %+v, err %v)", mapping.Field, style, err)
+		}
+		if err := f.SetCellValue(sheet, cell, value); err != nil {
+			t.Fatalf("SetCellValue: %v", err)
+		}
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	f.Close()
+
+	profile.HeaderRow = headerRow
+	items, _, err := ParseExcelToMItems(context.Background(), path, profile)
+	if err != nil {
+		t.Fatalf("ParseExcelToMItems: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("parsed %d items, want 1", len(items))
+	}
+	item := items[0]
+	got := map[string]float64{"PriceBase": item.PriceBase}
+	if item.WholesaleMinQty != nil {
+		got["WholesaleMinQty"] = *item.WholesaleMinQty
+	}
+	if item.WholesaleUnitPrice != nil {
+		got["WholesaleUnitPrice"] = *item.WholesaleUnitPrice
+	}
+	for field, want := range values {
+		if got[field] != want {
+			t.Errorf("%s = %v, want %v", field, got[field], want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package excel
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	SeverityError   = "error"   // Baris ditolak
+	SeverityWarning = "warning" // Nilai dilewati, baris tetap diimpor
+)
+
+// Rule validasi yang bisa dilanggar sebuah cell
+const (
+	RuleRequired   = "required"   // Kolom wajib kosong
+	RuleType       = "type"       // Nilai tidak sesuai tipe field (number, integer, bool)
+	RuleSupplier   = "supplier"   // Supplier tidak ditemukan atau ambigu di m_supp
+	RuleConstraint = "constraint" // Nilai melanggar batasan kolom tabel (panjang, presisi, NOT NULL)
+	RuleDatabase   = "database"   // Baris ditolak database saat dry run
+	RuleStaging    = "staging"    // Baris ditolak validasi SQL di tabel staging (duplikat, foreign key, constraint)
+	RuleBlank      = "blank"      // Semua kolom yang di-mapping kosong tetapi kolom lain berisi, baris dilewati
+)
+
+// ValidationIssue satu masalah validasi pada cell Excel
+type ValidationIssue struct {
+	Sheet    string `json:"sheet"`    // Nama sheet
+	Row      int    `json:"row"`      // Nomor baris di Excel (1-based)
+	Cell     string `json:"cell"`     // Alamat cell di sheet, misalnya D15; kosong jika kolom tidak ada
+	Column   string `json:"column"`   // Header kolom di sheet
+	Field    string `json:"field"`    // Field struct tujuan
+	Value    string `json:"value"`    // Nilai mentah di cell
+	Rule     string `json:"rule"`     // Rule yang dilanggar
+	Message  string `json:"message"`  // Penjelasan untuk tim data-entry
+	Severity string `json:"severity"` // SeverityError atau SeverityWarning
+}
+
+// ValidationResult hasil validasi satu file Excel
+type ValidationResult struct {
+	RowsRead     int
+	RowsRejected int
+	Issues       []ValidationIssue
+
+	columns map[string]map[string]fieldColumn // Nama sheet -> kolom sheet untuk setiap field yang di-mapping
+}
+
+// newValidationResult membuat hasil validasi kosong
+func newValidationResult() *ValidationResult {
+	return &ValidationResult{columns: make(map[string]map[string]fieldColumn)}
+}
+
+// fieldColumn posisi dan header kolom sheet untuk satu field
+type fieldColumn struct {
+	index  int
+	header string
+	fixed  bool // Field punya default atau nilai tetap, sehingga tetap terisi tanpa kolom
+}
+
+// FieldIssue membuat issue untuk field pada baris sheet tertentu, lengkap dengan alamat cell dan header kolomnya
+func (r *ValidationResult) FieldIssue(sheet string, row int, field string) ValidationIssue {
+	issue := ValidationIssue{Sheet: sheet, Row: row, Column: field, Field: field}
+	if column, ok := r.columns[sheet][field]; ok {
+		issue.Cell = cellName(column.index, row)
+		issue.Column = column.header
+	}
+	return issue
+}
+
+// bound memeriksa apakah field terisi dari workbook pada sheet: kolomnya ditemukan, atau
+// field punya default atau nilai tetap
+func (r *ValidationResult) bound(sheet, field string) bool {
+	column, ok := r.columns[sheet][field]
+	return ok && (column.index >= 0 || column.fixed)
+}
+
+// cellName mengubah index kolom (0-based) dan nomor baris menjadi alamat cell, misalnya D15
+func cellName(column, row int) string {
+	if column < 0 {
+		return ""
+	}
+	name, err := excelize.CoordinatesToCellName(column+1, row)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// Add menambahkan issue ke hasil validasi
+func (r *ValidationResult) Add(issue ValidationIssue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// Reject mencatat satu baris yang ditolak beserta issue penyebabnya
+func (r *ValidationResult) Reject(issue ValidationIssue) {
+	r.RowsRejected++
+	r.Add(issue)
+}
+
+// ErrorCount jumlah issue dengan severity error
+func (r *ValidationResult) ErrorCount() int {
+	return r.count(SeverityError)
+}
+
+// WarningCount jumlah issue dengan severity warning
+func (r *ValidationResult) WarningCount() int {
+	return r.count(SeverityWarning)
+}
+
+// RowsSkipped jumlah baris yang dilewati karena semua kolom yang di-mapping kosong padahal
+// kolom lain berisi (RuleBlank)
+func (r *ValidationResult) RowsSkipped() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Rule == RuleBlank {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors mengecek apakah ada issue dengan severity error
+func (r *ValidationResult) HasErrors() bool {
+	return r.ErrorCount() > 0
+}
+
+func (r *ValidationResult) count(severity string) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+var reportHeaders = []string{"Sheet", "Row", "Cell", "Column", "Field", "Value", "Rule", "Severity", "Message"}
+
+func (issue ValidationIssue) record() []string {
+	return []string{issue.Sheet, strconv.Itoa(issue.Row), issue.Cell, issue.Column, issue.Field, issue.Value, issue.Rule, issue.Severity, issue.Message}
+}
+
+// WriteValidationReport menulis daftar issue ke file CSV, JSON atau XLSX sesuai ekstensi path
+func WriteValidationReport(result *ValidationResult, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return writeReportCSV(result, path)
+	case ".json":
+		return writeReportJSON(result, path)
+	case ".xlsx":
+		return writeReportXLSX(result, path)
+	default:
+		return fmt.Errorf("unsupported report format '%s', use .csv, .json or .xlsx", filepath.Ext(path))
+	}
+}
+
+func writeReportCSV(result *ValidationResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %v", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write(reportHeaders); err != nil {
+		return err
+	}
+	for _, issue := range result.Issues {
+		if err := w.Write(issue.record()); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeReportJSON(result *ValidationResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %v", err)
+	}
+	defer file.Close()
+
+	issues := result.Issues
+	if issues == nil {
+		issues = []ValidationIssue{}
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+func writeReportXLSX(result *ValidationResult, path string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Validation"
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	errorStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "9C0006"}})
+	if err != nil {
+		return err
+	}
+
+	headers := make([]interface{}, len(reportHeaders))
+	for i, header := range reportHeaders {
+		headers[i] = header
+	}
+	if err := f.SetSheetRow(sheet, "A1", &headers); err != nil {
+		return err
+	}
+	if err := f.SetRowStyle(sheet, 1, 1, headerStyle); err != nil {
+		return err
+	}
+
+	for i, issue := range result.Issues {
+		rowNum := i + 2
+		values := []interface{}{issue.Sheet, issue.Row, issue.Cell, issue.Column, issue.Field, issue.Value, issue.Rule, issue.Severity, issue.Message}
+		cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+		if issue.Severity == SeverityError {
+			if err := f.SetRowStyle(sheet, rowNum, rowNum, errorStyle); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("error saving report file: %v", err)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ toolchain go1.24.6
 require (
 	github.com/lib/pq v1.10.9
 	github.com/xuri/excelize/v2 v2.9.1
+	golang.org/x/text v0.25.0
 )
 
 require (
@@ -17,6 +18,5 @@ require (
 	github.com/xuri/nfp v0.0.1 // indirect
 	golang.org/x/crypto v0.38.0 // indirect
 	golang.org/x/net v0.40.0 // indirect
-	golang.org/x/text v0.25.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
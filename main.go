@@ -1,94 +1,414 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"os/user"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	"excel-seeder/config"
-	"excel-seeder/database"
 	"excel-seeder/excel"
 	"excel-seeder/models"
 )
 
+// Exit code program, agar script bisa membedakan data yang tidak valid dari error infrastruktur
+const (
+	exitOK          = 0
+	exitError       = 1   // Error infrastruktur: config, file, koneksi atau query database
+	exitUsage       = 2   // Perintah atau flag tidak valid
+	exitValidation  = 3   // Data workbook gagal validasi
+	exitInterrupted = 130 // Dihentikan dengan SIGINT/SIGTERM
+)
+
+// command satu subcommand excel-seeder
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// layoutFlags flag format file input dan letak data di workbook, dipakai semua perintah yang
+// membaca workbook. Nilai kosong berarti mengikuti mapping profile.
+type layoutFlags struct {
+	headerRow    int
+	headerRows   int
+	firstRow     int
+	lastRow      int
+	stopAtBlank  bool
+	skipPrefixes string
+	sheets       string
+	sheetField   string
+	input        config.InputOptions
+}
+
+// register mendaftarkan flag letak data ke flag set perintah
+func (l *layoutFlags) register(flags *flag.FlagSet) {
+	flags.IntVar(&l.headerRow, "header-row", 0, "Row number (1-based) of the header row, overriding header_row of the profile; workbooks made by the template command use 2")
+	flags.IntVar(&l.headerRows, "header-rows", 0, "Number of header rows starting at the header row; their text is joined per column, merged cells included")
+	flags.IntVar(&l.firstRow, "first-row", 0, "Row number (1-based) of the first data row; 0 starts right after the header rows")
+	flags.IntVar(&l.lastRow, "last-row", 0, "Row number (1-based) of the last data row; 0 reads to the end of the sheet")
+	flags.BoolVar(&l.stopAtBlank, "stop-at-blank", false, "Stop reading a sheet at its first completely blank data row")
+	flags.StringVar(&l.skipPrefixes, "skip-prefix", "", "Comma-separated texts; rows whose first non-empty cell starts with one of them are skipped, for example TOTAL,SUBTOTAL")
+	flags.StringVar(&l.sheets, "sheet", "", "Comma-separated sheet names or 1-based sheet numbers to import, or '*' for every visible sheet; empty uses the profile")
+	flags.StringVar(&l.sheetField, "sheet-field", "", "Field filled with the sheet name for every row, for example Spec or MBuID")
+	flags.StringVar(&l.input.Format, "format", "", "Input format: 'xlsx', 'csv' or 'tsv'; empty uses the file extension (.csv/.txt, .tsv/.tab, otherwise xlsx)")
+	flags.StringVar(&l.input.Delimiter, "delimiter", "", "CSV column delimiter, for example ';' or 'tab'; empty detects it from the file")
+	flags.StringVar(&l.input.Encoding, "encoding", "", "CSV encoding, for example 'utf-8', 'utf-16le' or 'windows-1252'; empty detects it from the file")
+	flags.StringVar(&l.input.Quote, "quote", "", "CSV quote character: '\"', \"'\" or 'none'; empty detects it from the file")
+}
+
+// apply menimpa pengaturan profile dengan flag yang diisi. Sheet yang dipilih -sheet tetap
+// memakai mapping per sheet dari profile jika ada.
+func (l layoutFlags) apply(profile *config.MappingProfile) {
+	if l.headerRow > 0 {
+		profile.HeaderRow = l.headerRow
+	}
+	if l.headerRows > 0 {
+		profile.HeaderRows = l.headerRows
+	}
+	if l.firstRow > 0 {
+		profile.FirstDataRow = l.firstRow
+	}
+	if l.lastRow > 0 {
+		profile.LastDataRow = l.lastRow
+	}
+	if l.stopAtBlank {
+		profile.StopAtBlank = true
+	}
+	if l.skipPrefixes != "" {
+		profile.SkipPrefixes = strings.Split(l.skipPrefixes, ",")
+	}
+	if l.sheetField != "" {
+		profile.SheetField = l.sheetField
+	}
+	if err := excel.CheckInputOptions(l.input); err != nil {
+		usageFailf("Invalid input option: %v", err)
+	}
+	if l.input.Format != "" {
+		profile.Input.Format = l.input.Format
+	}
+	if l.input.Delimiter != "" {
+		profile.Input.Delimiter = l.input.Delimiter
+	}
+	if l.input.Encoding != "" {
+		profile.Input.Encoding = l.input.Encoding
+	}
+	if l.input.Quote != "" {
+		profile.Input.Quote = l.input.Quote
+	}
+
+	switch l.sheets {
+	case "":
+	case "*":
+		profile.Sheets = nil
+		profile.AllSheets = true
+	default:
+		configured := make(map[string]config.SheetMapping, len(profile.Sheets))
+		for _, sheet := range profile.Sheets {
+			configured[sheet.Sheet] = sheet
+		}
+		profile.Sheets = nil
+		profile.AllSheets = false
+		for _, name := range strings.Split(l.sheets, ",") {
+			name = strings.TrimSpace(name)
+			sheet, ok := configured[name]
+			if !ok {
+				sheet = config.SheetMapping{Sheet: name}
+			}
+			profile.Sheets = append(profile.Sheets, sheet)
+		}
+	}
+}
+
+// commands daftar subcommand, berurutan sesuai tampilan di help
+var commands = []command{
+	{"import", "Validate a workbook and write it to the database", runImport},
+	{"validate", "Validate a workbook without writing anything", runValidate},
+	{"seed-sql", "Validate a workbook and write it to a SQL seeder file", runSeedSQL},
+	{"export", "Export m_item or m_supp from the database to a workbook", runExport},
+	{"diff", "Compare a workbook with the current contents of m_item", runDiff},
+	{"template", "Write a blank import workbook for the mapping profile", runTemplate},
+	{"rollback", "Undo the changes of a previous import run", runRollback},
+}
+
+// globalFlags flag yang berlaku untuk semua perintah. Bisa ditulis sebelum nama perintah
+// (excel-seeder -config=prod.yaml import) maupun sesudahnya.
+var global struct {
+	config  string
+	profile string
+}
+
 func main() {
-	// Command line flags
-	var (
-		configPath = flag.String("config", "config.local.yaml", "Path to config file")
-		excelPath  = flag.String("excel", "file/MasterBarang.xlsx", "Path to Excel file")
-		outputMode = flag.String("output", "database", "Output mode: 'database' for direct insert, 'seeder' for SQL file generation")
-		seederPath = flag.String("seeder-path", "seeder/seeder.sql", "Path for generated seeder file (when output=seeder)")
-	)
+	flag.StringVar(&global.config, "config", "config.local.yaml", "Path to config file")
+	flag.StringVar(&global.profile, "profile", "", "Column mapping profile from the config file; empty uses the built-in mapping for -entity")
+	flag.Usage = usage
 	flag.Parse()
 
-	log.Printf("Starting Excel to PostgreSQL parser...")
-	log.Printf("Config: %s", *configPath)
-	log.Printf("Excel: %s", *excelPath)
-	log.Printf("Output mode: %s", *outputMode)
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	name := args[0]
+	if name == "help" {
+		if len(args) < 2 {
+			usage()
+			return
+		}
+		name, args = args[1], []string{args[1], "-h"}
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			cmd.run(args[1:])
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n", name)
+	usage()
+	os.Exit(exitUsage)
+}
+
+// usage menampilkan daftar perintah dan flag global
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [global flags] <command> [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nGlobal flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nRun '%s help <command>' for the flags of a command.\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(out, "\nExit codes: %d success, %d error, %d invalid usage, %d validation failed, %d interrupted\n",
+		exitOK, exitError, exitUsage, exitValidation, exitInterrupted)
+}
+
+// newFlagSet membuat flag set untuk satu perintah, lengkap dengan flag global dan help text
+func newFlagSet(name, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.StringVar(&global.config, "config", global.config, "Path to config file")
+	flags.StringVar(&global.profile, "profile", global.profile, "Column mapping profile from the config file; empty uses the built-in mapping for -entity")
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Usage: %s %s [flags]\n\n%s\n\nFlags:\n", filepath.Base(os.Args[0]), name, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags mem-parse argumen perintah dan menolak argumen sisa yang bukan flag
+func parseFlags(flags *flag.FlagSet, args []string) {
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		fmt.Fprintf(flags.Output(), "Unexpected argument '%s'\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+}
+
+// loadProfile memvalidasi entity lalu memuat config dan mapping profile yang dipilih
+func loadProfile(entity string) (*config.Config, config.MappingProfile) {
+	if entity != "item" && entity != "supplier" {
+		usageFailf("Invalid entity: %s. Use 'item' or 'supplier'", entity)
+	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	cfg, err := config.LoadConfig(global.config)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 	log.Printf("Configuration loaded successfully")
 
-	// Parse Excel file
-	log.Printf("Parsing Excel file: %s", *excelPath)
-	items, err := excel.ParseExcelToMItems(*excelPath)
+	profile, err := selectProfile(cfg, global.profile, entity)
 	if err != nil {
-		log.Fatalf("Failed to parse Excel file: %v", err)
+		log.Fatalf("Failed to load mapping profile: %v", err)
 	}
-	log.Printf("Successfully parsed %d items from Excel", len(items))
+	return cfg, profile
+}
+
+// currentRun run import yang sedang dicatat di import_runs beserta angka yang dibutuhkan
+// saat run ditutup; run bernilai nil jika riwayat tidak dicatat
+var currentRun struct {
+	run        *models.ImportRun
+	validation *excel.ValidationResult
+	committed  int // Baris yang sudah commit
+}
 
-	if len(items) == 0 {
-		log.Printf("No items found in Excel file")
+// finishRun menutup run yang sedang dicatat dengan outcome dan pesan tertentu
+func finishRun(outcome, message string) {
+	run := currentRun.run
+	if run == nil {
 		return
 	}
+	currentRun.run = nil
 
-	// Handle output based on mode
-	switch *outputMode {
-	case "database":
-		// Direct database insertion
-		log.Printf("Connecting to database...")
-		db, err := database.ConnectDB(cfg)
-		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
+	if validation := currentRun.validation; validation != nil {
+		run.RowsParsed = validation.RowsRead
+		run.RowsRejected = validation.RowsRejected
+	}
+	run.RowsInserted = currentRun.committed
+	if err := run.Finish(outcome, message); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	log.Printf("Import run %d recorded in import_runs: %s", run.ID, outcome)
+}
+
+// fatalf seperti log.Fatalf (exit code exitError), tetapi mencatat run yang sedang
+// berjalan sebagai gagal lebih dulu
+func fatalf(format string, args ...interface{}) {
+	finishRun(models.RunFailed, fmt.Sprintf(format, args...))
+	log.Fatalf(format, args...)
+}
+
+// validationFailf menghentikan program dengan exit code exitValidation karena data tidak valid
+func validationFailf(format string, args ...interface{}) {
+	finishRun(models.RunFailed, fmt.Sprintf(format, args...))
+	log.Printf(format, args...)
+	os.Exit(exitValidation)
+}
+
+// usageFailf menghentikan program dengan exit code exitUsage karena flag tidak valid
+func usageFailf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+	os.Exit(exitUsage)
+}
+
+// operatorName mengembalikan nama operator dari flag, atau user OS jika kosong
+func operatorName(name string) string {
+	if name != "" {
+		return name
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+// signalContext mengembalikan context yang dibatalkan saat menerima SIGINT atau SIGTERM,
+// sehingga batch yang sedang berjalan di-rollback dan import berhenti dengan rapi.
+// Sinyal kedua menghentikan program seketika.
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		log.Printf("Received %s, stopping after rolling back the batch in progress (send again to force quit)", sig)
+		cancel()
+	}()
+	return ctx
+}
+
+// exitIfInterrupted menghentikan program dengan exit code exitInterrupted jika ctx sudah dibatalkan,
+// setelah mencatat berapa baris yang sudah ditulis (commit) sebelum pembatalan
+func exitIfInterrupted(ctx context.Context, err error, committed int, entity string) {
+	if ctx.Err() == nil {
+		return
+	}
+	var interrupted *models.InterruptedError
+	if errors.As(err, &interrupted) {
+		committed += interrupted.Committed
+	}
+	finishRun(models.RunInterrupted, err.Error())
+	log.Printf("Interrupted: %v", err)
+	log.Printf("Summary: %d %ss written before the interrupt, nothing from the batch in progress was written", committed, entity)
+	os.Exit(exitInterrupted)
+}
+
+// writeValidationOutputs mencatat ringkasan validasi dan menulis laporan serta file error jika diminta
+func writeValidationOutputs(validation *excel.ValidationResult, reportPath, errorFile, excelPath string, profile config.MappingProfile) {
+	log.Printf("Validation: %d rows read, %d rejected, %d skipped, %d errors, %d warnings",
+		validation.RowsRead, validation.RowsRejected, validation.RowsSkipped(), validation.ErrorCount(), validation.WarningCount())
+	if reportPath != "" {
+		if err := excel.WriteValidationReport(validation, reportPath); err != nil {
+			fatalf("Failed to write validation report: %v", err)
+		}
+		log.Printf("Validation report written to: %s", reportPath)
+	}
+	if errorFile != "" && validation.RowsRejected+validation.RowsSkipped() > 0 {
+		if err := excel.WriteErrorWorkbook(excelPath, profile, validation, errorFile); err != nil {
+			fatalf("Failed to write error file: %v", err)
 		}
-		defer db.Close()
-		log.Printf("Database connection established")
+		log.Printf("%d rejected and %d skipped rows written to: %s", validation.RowsRejected, validation.RowsSkipped(), errorFile)
+	}
+}
 
-		log.Printf("Starting batch insert to database...")
-		err = models.InsertMItems(db, items)
-		if err != nil {
-			log.Fatalf("Failed to insert items: %v", err)
+// selectProfile memilih mapping profile dari config, atau mapping bawaan jika name kosong
+func selectProfile(cfg *config.Config, name, entity string) (config.MappingProfile, error) {
+	if name == "" {
+		if entity == "supplier" {
+			return excel.DefaultSupplierProfile(), nil
 		}
-		log.Printf("Successfully inserted %d items to database", len(items))
+		return excel.DefaultItemProfile(), nil
+	}
 
-	case "seeder":
-		// Generate SQL seeder file
-		log.Printf("Generating SQL seeder file...")
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return config.MappingProfile{}, err
+	}
+	if profile.Entity != "" && profile.Entity != entity {
+		return config.MappingProfile{}, fmt.Errorf("profile '%s' is for entity '%s', not '%s'", name, profile.Entity, entity)
+	}
+	log.Printf("Using mapping profile: %s", name)
 
-		seederDir := filepath.Dir(*seederPath)
-		err := createDirIfNotExists(seederDir)
-		if err != nil {
-			log.Fatalf("Failed to create seeder directory: %v", err)
-		}
+	return profile, nil
+}
 
-		err = models.GenerateSeederSQL(items, *seederPath)
-		if err != nil {
-			log.Fatalf("Failed to generate seeder file: %v", err)
+// rowRef posisi record di workbook: nama sheet dan nomor baris Excel
+type rowRef struct {
+	sheet string
+	row   int
+}
+
+func itemSourceRow(item models.MItem) rowRef { return rowRef{item.SourceSheet, item.SourceRow} }
+func suppSourceRow(supp models.MSupp) rowRef { return rowRef{supp.SourceSheet, supp.SourceRow} }
+
+// rejectRowErrors mencatat row error dari models sebagai issue validasi dan
+// membuang baris yang gagal dari records
+func rejectRowErrors[T any](records []T, rowErrors []models.RowError, validation *excel.ValidationResult, rule string, sourceRow func(T) rowRef) []T {
+	if len(rowErrors) == 0 {
+		return records
+	}
+
+	var zero T
+	rejected := make(map[int]bool)
+	for _, rowErr := range rowErrors {
+		ref := sourceRow(records[rowErr.Index])
+		log.Printf("Row %d: %s, skipping", ref.row, rowErr.Message)
+
+		issue := validation.FieldIssue(ref.sheet, ref.row, models.FieldForColumn(zero, rowErr.Column))
+		if rowErr.Column == "" {
+			issue = excel.ValidationIssue{Sheet: ref.sheet, Row: ref.row}
 		}
-		log.Printf("Successfully generated seeder file: %s", *seederPath)
-		log.Printf("You can run the seeder with: psql -d your_database -f %s", *seederPath)
+		issue.Value = rowErr.Value
+		issue.Rule = rule
+		issue.Message = rowErr.Message
+		issue.Severity = excel.SeverityError
 
-	default:
-		log.Fatalf("Invalid output mode: %s. Use 'database' or 'seeder'", *outputMode)
+		if rejected[rowErr.Index] {
+			validation.Add(issue)
+			continue
+		}
+		rejected[rowErr.Index] = true
+		validation.Reject(issue)
 	}
 
-	log.Printf("Process completed successfully!")
+	kept := make([]T, 0, len(records)-len(rejected))
+	for i, record := range records {
+		if !rejected[i] {
+			kept = append(kept, record)
+		}
+	}
+	return kept
 }
 
 func createDirIfNotExists(dir string) error {
@@ -0,0 +1,496 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// table definisi tabel tujuan import yang dipakai bersama oleh batch insert dan seeder
+type table struct {
+	Name             string
+	Columns          []sqlColumn
+	Constraints      map[string]columnConstraint // Batasan kolom sesuai migration, untuk validasi sebelum insert
+	UpsertKeys       map[string]string           // Nilai -upsert-key yang diizinkan -> nama kolom
+	ForeignKeys      map[string]string           // Kolom -> tabel referensi (dicocokkan ke id), dicek di staging
+	Defaults         map[string]interface{}      // Kolom -> nilai pengganti NULL, sama dengan DEFAULT di migration
+	DefaultUpsertKey string
+}
+
+// withDefaults mengganti nilai kosong di row dengan t.Defaults. INSERT yang menyebut kolom
+// dengan NULL eksplisit tidak memakai DEFAULT kolom di database, sehingga default-nya diisi di sini.
+func (t table) withDefaults(row []interface{}) []interface{} {
+	for column, value := range t.Defaults {
+		i := columnIndex(t.Columns, column)
+		if v := reflect.ValueOf(row[i]); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+			row[i] = value
+		}
+	}
+	return row
+}
+
+// batchSize menghitung jumlah baris per batch berdasarkan limit parameter PostgreSQL
+func (t table) batchSize() int {
+	return PostgreSQLParamLimit / len(t.Columns)
+}
+
+// upsertKey mengembalikan nama kolom natural key untuk mode upsert
+func (t table) upsertKey(opts InsertOptions) (string, error) {
+	key := opts.UpsertKey
+	if key == "" {
+		key = t.DefaultUpsertKey
+	}
+	column, ok := t.UpsertKeys[key]
+	if !ok {
+		allowed := make([]string, 0, len(t.UpsertKeys))
+		for k := range t.UpsertKeys {
+			allowed = append(allowed, k)
+		}
+		return "", fmt.Errorf("invalid upsert key '%s' for %s, use one of: %s", key, t.Name, strings.Join(allowed, ", "))
+	}
+	return column, nil
+}
+
+// insertRows memecah rows menjadi beberapa batch dan menginsert setiap batch,
+// atau menyerahkan seluruh rows ke loader lain sesuai opts.Loader.
+// Jika ctx dibatalkan, batch yang sedang berjalan di-rollback dan InterruptedError
+// dikembalikan dengan jumlah baris yang sudah commit.
+func insertRows(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case opts.Loader == LoaderCopy:
+		err = copyRows(ctx, db, t, rows, opts)
+	case opts.Loader == LoaderStaging:
+		err = stageAndMerge(ctx, db, t, rows, opts)
+	case opts.Atomic:
+		log.Printf("Using batch size: %d (calculated from %d/%d)", t.batchSize(), PostgreSQLParamLimit, len(t.Columns))
+		err = insertRowsAtomic(ctx, db, t, rows, opts)
+	default:
+		log.Printf("Using batch size: %d (calculated from %d/%d)", t.batchSize(), PostgreSQLParamLimit, len(t.Columns))
+		if err = prepareInTx(ctx, db, opts); err != nil {
+			break
+		}
+		if workers := poolWorkers(db, opts.Workers); workers > 1 {
+			err = insertRowsConcurrent(ctx, db, t, rows, opts, workers)
+		} else {
+			err = insertRowsSequential(ctx, db, t, rows, opts)
+		}
+	}
+
+	// Loader yang memakai satu transaction tidak menyimpan apa pun jika dibatalkan
+	var interrupted *InterruptedError
+	if err != nil && ctx.Err() != nil && !errors.As(err, &interrupted) {
+		return &InterruptedError{Err: ctx.Err()}
+	}
+	return err
+}
+
+// prepareInTx menjalankan opts.prepare di transaction tersendiri lalu commit, untuk loader
+// yang tidak menulis semua batch di dalam satu transaction
+func prepareInTx(ctx context.Context, db *sql.DB, opts InsertOptions) error {
+	if opts.prepare == nil {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := opts.prepare(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// insertRowsSequential menginsert batch satu per satu, masing-masing dalam transaction sendiri
+func insertRowsSequential(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) error {
+	batchSize := t.batchSize()
+	for i := 0; i < len(rows); i += batchSize {
+		if ctx.Err() != nil {
+			return &InterruptedError{Committed: i, Err: ctx.Err()}
+		}
+
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		batch := rows[i:end]
+		updated, inserted, err := insertBatch(ctx, db, t, batch, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Printf("Batch %d-%d rolled back", i+1, end)
+				return &InterruptedError{Committed: i, Err: ctx.Err()}
+			}
+			return fmt.Errorf("error inserting batch %d-%d: %v", i+1, end, err)
+		}
+		if opts.Mode == ModeUpsert {
+			log.Printf("Upsert batch %d-%d: %d updated, %d inserted", i+1, end, updated, inserted)
+		}
+		log.Printf("Successfully inserted batch %d-%d (%d items)", i+1, end, len(batch))
+		if opts.OnCommit != nil {
+			opts.OnCommit(i, end)
+		}
+	}
+
+	return nil
+}
+
+// InterruptedError import yang berhenti karena context dibatalkan, misalnya oleh Ctrl+C.
+// Baris yang sudah commit sebelum pembatalan tetap tersimpan di database.
+type InterruptedError struct {
+	Committed int   // Jumlah baris yang sudah commit
+	Err       error // Penyebab pembatalan, biasanya context.Canceled
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("import interrupted after %d rows were committed: %v", e.Committed, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError batch yang gagal pada import atomic
+type BatchError struct {
+	Start int // Nomor baris pertama di batch (1-based, urutan data yang diimpor)
+	End   int
+	Err   error
+}
+
+// AtomicImportError import atomic yang dibatalkan karena satu atau lebih batch gagal
+type AtomicImportError struct {
+	Batches int          // Jumlah seluruh batch
+	Failed  []BatchError // Batch yang gagal
+}
+
+func (e *AtomicImportError) Error() string {
+	parts := make([]string, len(e.Failed))
+	for i, failed := range e.Failed {
+		parts[i] = fmt.Sprintf("batch %d-%d: %v", failed.Start, failed.End, failed.Err)
+	}
+	return fmt.Sprintf("%d of %d batches failed, all changes rolled back (%s)", len(e.Failed), e.Batches, strings.Join(parts, "; "))
+}
+
+// insertRowsAtomic menjalankan semua batch di dalam satu transaction. Setiap batch memakai
+// savepoint sehingga batch berikutnya tetap dicoba dan semua batch yang gagal bisa dilaporkan,
+// lalu transaction di-rollback jika ada yang gagal. opts.prepare (misalnya pembuatan supplier)
+// berjalan di transaction yang sama sehingga ikut di-rollback.
+func insertRowsAtomic(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if opts.prepare != nil {
+		if err := opts.prepare(tx); err != nil {
+			return err
+		}
+	}
+
+	batchSize := t.batchSize()
+	importErr := &AtomicImportError{}
+	for i := 0; i < len(rows); i += batchSize {
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		importErr.Batches++
+
+		batch := rows[i:end]
+		batchErr := execInSavepoint(tx, "import_batch", func() error {
+			updated, inserted, err := execBatch(tx, t, batch, opts)
+			if err == nil && opts.Mode == ModeUpsert {
+				log.Printf("Upsert batch %d-%d: %d updated, %d inserted", i+1, end, updated, inserted)
+			}
+			return err
+		})
+		if errors.Is(batchErr, errSavepoint) {
+			return batchErr
+		}
+		if batchErr != nil {
+			log.Printf("Batch %d-%d failed: %v", i+1, end, batchErr)
+			importErr.Failed = append(importErr.Failed, BatchError{Start: i + 1, End: end, Err: batchErr})
+			continue
+		}
+		log.Printf("Staged batch %d-%d (%d items), waiting for commit", i+1, end, len(batch))
+	}
+
+	if len(importErr.Failed) > 0 {
+		log.Printf("Atomic import failed: %d of %d batches failed, rolling back all batches", len(importErr.Failed), importErr.Batches)
+		return importErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	log.Printf("Atomic import committed: %d batches, %d items", importErr.Batches, len(rows))
+	return nil
+}
+
+// insertBatch melakukan insert untuk satu batch dalam transaction tersendiri
+func insertBatch(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) (updated, inserted int, err error) {
+	if len(rows) == 0 {
+		return 0, 0, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	updated, inserted, err = execBatch(tx, t, rows, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return updated, inserted, nil
+}
+
+// execBatch menjalankan multi-value INSERT untuk satu batch di dalam tx, atau upsert
+// berdasarkan opts.UpsertKey jika mode upsert dipilih. Mengembalikan jumlah baris
+// yang di-update dan di-insert. Jika opts.RunID diisi, perubahan dicatat ke import_run_rows.
+func execBatch(tx *sql.Tx, t table, rows [][]interface{}, opts InsertOptions) (int, int, error) {
+	columnCount := len(t.Columns)
+	valuesPlaceholders := make([]string, len(rows))
+	args := make([]interface{}, 0, len(rows)*columnCount)
+
+	for i, row := range rows {
+		paramStart := i * columnCount
+		placeholders := make([]string, columnCount)
+		for j := 0; j < columnCount; j++ {
+			placeholders[j] = fmt.Sprintf("$%d", paramStart+j+1)
+			if opts.Mode == ModeUpsert {
+				placeholders[j] += "::" + t.Columns[j].Type
+			}
+		}
+		valuesPlaceholders[i] = "(" + strings.Join(placeholders, ", ") + ")"
+
+		// Add arguments in the same order as the columns
+		args = append(args, row...)
+	}
+
+	if opts.Mode == ModeUpsert {
+		key, err := t.upsertKey(opts)
+		if err != nil {
+			return 0, 0, err
+		}
+		query := buildUpsertQuery(t.Name, t.Columns, key, valuesPlaceholders, opts.RunID)
+
+		var updated, inserted int
+		err = tx.QueryRow(query, args...).Scan(&updated, &inserted)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error executing batch upsert: %w", err)
+		}
+		return updated, inserted, nil
+	}
+
+	if opts.RunID > 0 {
+		var inserted int
+		err := tx.QueryRow(buildTrackedInsert(t.Name, t.Columns, "VALUES "+strings.Join(valuesPlaceholders, ", "), opts.RunID), args...).Scan(&inserted)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error executing batch insert: %w", err)
+		}
+		return 0, inserted, nil
+	}
+
+	// Build multi-value INSERT query
+	query := "INSERT INTO " + t.Name + " (" + columnNames(t.Columns) + ") VALUES " + strings.Join(valuesPlaceholders, ", ")
+
+	_, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error executing batch insert: %w", err)
+	}
+	return 0, len(rows), nil
+}
+
+// generateSeeder membuat file SQL seeder untuk tabel t. Preamble (jika ada) ditulis
+// sebelum batch pertama, misalnya untuk membuat data referensi yang dibutuhkan.
+func generateSeeder(t table, rows [][]interface{}, outputPath string, preamble string, opts InsertOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if opts.Mode == ModeUpsert {
+		if _, err := t.upsertKey(opts); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("error creating seeder file: %v", err)
+	}
+	defer file.Close()
+
+	// Write header
+	_, err = file.WriteString(fmt.Sprintf("-- Generated seeder file for %s table\n", t.Name))
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(fmt.Sprintf("-- Generated at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(fmt.Sprintf("-- Total items: %d\n\n", len(rows)))
+	if err != nil {
+		return err
+	}
+	if preamble != "" {
+		_, err = file.WriteString(preamble)
+		if err != nil {
+			return err
+		}
+	}
+
+	batchSize := t.batchSize()
+	log.Printf("Generating SQL seeder with batch size: %d", batchSize)
+
+	for i := 0; i < len(rows); i += batchSize {
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		batch := rows[i:end]
+		if err := writeBatchSQL(file, t, batch, i+1, opts); err != nil {
+			return fmt.Errorf("error writing batch %d-%d: %v", i+1, end, err)
+		}
+	}
+
+	log.Printf("Successfully generated seeder file: %s", outputPath)
+	return nil
+}
+
+// writeBatchSQL menulis satu batch INSERT (atau upsert) statement ke file
+func writeBatchSQL(file *os.File, t table, rows [][]interface{}, batchNum int, opts InsertOptions) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// Write batch comment
+	_, err := file.WriteString(fmt.Sprintf("-- Batch %d (%d items)\n", batchNum, len(rows)))
+	if err != nil {
+		return err
+	}
+
+	tuples := make([]string, len(rows))
+	for i, row := range rows {
+		// Format values
+		values := make([]string, len(row))
+		for j, value := range row {
+			values[j] = formatSQLValue(value)
+			if opts.Mode == ModeUpsert {
+				values[j] += "::" + t.Columns[j].Type
+			}
+		}
+		tuples[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+
+	// Write INSERT statement
+	if opts.Mode == ModeUpsert {
+		key, err := t.upsertKey(opts)
+		if err != nil {
+			return err
+		}
+		_, err = file.WriteString(buildUpsertQuery(t.Name, t.Columns, key, tuples, 0))
+		if err != nil {
+			return err
+		}
+	} else {
+		_, err = file.WriteString("INSERT INTO " + t.Name + " (\n\t" + columnNames(t.Columns) + "\n) VALUES\n\t" + strings.Join(tuples, ",\n\t"))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = file.WriteString(";\n\n")
+	return err
+}
+
+// sqlExpr ekspresi SQL mentah yang ditulis apa adanya ke file seeder, misalnya subquery
+type sqlExpr string
+
+// formatSQLValue memformat nilai untuk SQL statement
+func formatSQLValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case sqlExpr:
+		return string(v)
+	case *string:
+		if v == nil {
+			return "NULL"
+		}
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(*v, "'", "''"))
+	case string:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(v, "'", "''"))
+	case *int64:
+		if v == nil {
+			return "NULL"
+		}
+		return fmt.Sprintf("%d", *v)
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case *int32:
+		if v == nil {
+			return "NULL"
+		}
+		return fmt.Sprintf("%d", *v)
+	case int32:
+		return fmt.Sprintf("%d", v)
+	case *float64:
+		if v == nil {
+			return "NULL"
+		}
+		return fmt.Sprintf("%f", *v)
+	case float64:
+		return fmt.Sprintf("%f", v)
+	case *bool:
+		if v == nil {
+			return "NULL"
+		}
+		if *v {
+			return "true"
+		}
+		return "false"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case *time.Time:
+		if v == nil {
+			return "NULL"
+		}
+		return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05"))
+	case time.Time:
+		return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05"))
+	default:
+		return "NULL"
+	}
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+// columnConstraint batasan satu kolom sesuai definisi tabel di migration
+type columnConstraint struct {
+	MaxLength int  // varchar(n)
+	Precision int  // numeric(p, s)
+	Scale     int  // numeric(p, s)
+	Integer   bool // Kolom integer yang diisi dari nilai float64
+	NotNull   bool
+}
+
+// RowError satu baris yang akan (atau sudah) gagal ditulis ke database
+type RowError struct {
+	Index   int    // Index baris pada slice input
+	Column  string // Kolom database, kosong jika tidak diketahui
+	Value   string // Nilai yang melanggar, jika diketahui
+	Message string
+}
+
+// checkConstraints memeriksa setiap nilai terhadap t.Constraints tanpa menyentuh database
+func checkConstraints(t table, rows [][]interface{}) []RowError {
+	var rowErrors []RowError
+	for i, row := range rows {
+		for j, value := range row {
+			column := t.Columns[j].Name
+			constraint, ok := t.Constraints[column]
+			if !ok {
+				continue
+			}
+			if message := constraint.check(value); message != "" {
+				rowErrors = append(rowErrors, RowError{
+					Index:   i,
+					Column:  strings.Trim(column, `"`),
+					Value:   displayValue(value),
+					Message: fmt.Sprintf("%s %s", strings.Trim(column, `"`), message),
+				})
+			}
+		}
+	}
+	return rowErrors
+}
+
+// check mengembalikan pesan pelanggaran, atau string kosong jika nilai valid
+func (c columnConstraint) check(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		if c.NotNull {
+			return "must not be NULL"
+		}
+		return ""
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if c.MaxLength > 0 && utf8.RuneCountInString(v.String()) > c.MaxLength {
+			return fmt.Sprintf("exceeds %d characters (got %d)", c.MaxLength, utf8.RuneCountInString(v.String()))
+		}
+	case reflect.Float64:
+		number := v.Float()
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return "must be a finite number"
+		}
+		if c.Integer && (number != math.Trunc(number) || number > math.MaxInt32 || number < math.MinInt32) {
+			return "must be a whole number within integer range"
+		}
+		if c.Precision > 0 {
+			limit := math.Pow(10, float64(c.Precision-c.Scale))
+			rounded := math.Round(number*math.Pow(10, float64(c.Scale))) / math.Pow(10, float64(c.Scale))
+			if math.Abs(rounded) >= limit {
+				return fmt.Sprintf("exceeds numeric(%d,%d), absolute value must be less than %.0f", c.Precision, c.Scale, limit)
+			}
+		}
+	}
+	return ""
+}
+
+// displayValue memformat nilai kolom untuk pesan error
+func displayValue(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return ""
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Float64 {
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// dryRunRows menjalankan seluruh batch di dalam satu transaction yang selalu di-rollback.
+// Batch yang gagal diulang per baris (masing-masing dengan savepoint) untuk menemukan
+// baris mana yang ditolak database.
+func dryRunRows(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) ([]RowError, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			log.Printf("Warning: error rolling back dry run: %v", err)
+			return
+		}
+		log.Printf("Dry run: transaction rolled back, nothing was written to %s", t.Name)
+	}()
+
+	if opts.prepare != nil {
+		if err := opts.prepare(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	var rowErrors []RowError
+	batchSize := t.batchSize()
+	for i := 0; i < len(rows); i += batchSize {
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		batch := rows[i:end]
+		batchErr := execInSavepoint(tx, "dry_run_batch", func() error {
+			_, _, err := execBatch(tx, t, batch, opts)
+			return err
+		})
+		if batchErr == nil {
+			log.Printf("Dry run: batch %d-%d OK (%d items)", i+1, end, len(batch))
+			continue
+		}
+		if errors.Is(batchErr, errSavepoint) {
+			return nil, batchErr
+		}
+
+		log.Printf("Dry run: batch %d-%d failed (%v), checking rows one by one", i+1, end, batchErr)
+		failed := 0
+		for j, row := range batch {
+			rowErr := execInSavepoint(tx, "dry_run_row", func() error {
+				_, _, err := execBatch(tx, t, [][]interface{}{row}, opts)
+				return err
+			})
+			if rowErr == nil {
+				continue
+			}
+			if errors.Is(rowErr, errSavepoint) {
+				return nil, rowErr
+			}
+			failed++
+			rowErrors = append(rowErrors, RowError{Index: i + j, Column: pqErrorColumn(rowErr), Message: rowErr.Error()})
+		}
+		log.Printf("Dry run: batch %d-%d has %d failing rows", i+1, end, failed)
+	}
+
+	return rowErrors, nil
+}
+
+// errSavepoint menandai error saat membuat atau me-rollback savepoint (bukan error data)
+var errSavepoint = errors.New("savepoint error")
+
+// execInSavepoint menjalankan fn di dalam savepoint. Jika fn gagal, perubahan dibatalkan
+// sampai savepoint sehingga transaction tetap bisa dipakai.
+func execInSavepoint(tx *sql.Tx, name string, fn func() error) error {
+	if _, err := tx.Exec("SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("%w: %v", errSavepoint, err)
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT " + name); rbErr != nil {
+			return fmt.Errorf("%w: %v (after %v)", errSavepoint, rbErr, err)
+		}
+		return err
+	}
+	if _, err := tx.Exec("RELEASE SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("%w: %v", errSavepoint, err)
+	}
+	return nil
+}
+
+// pqErrorColumn mengambil nama kolom dari error PostgreSQL, jika ada
+func pqErrorColumn(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Column
+	}
+	return ""
+}
+
+// FieldForColumn mengembalikan nama field struct yang memiliki tag db sesuai kolom
+func FieldForColumn(record interface{}, column string) string {
+	t := reflect.TypeOf(record)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("db") == column {
+			return t.Field(i).Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestColumnConstraintCheck(t *testing.T) {
+	number := func(v float64) *float64 { return &v }
+	text := func(v string) *string { return &v }
+
+	tests := []struct {
+		name       string
+		constraint columnConstraint
+		value      interface{}
+		want       string // Bagian pesan yang diharapkan; kosong berarti nilai valid
+	}{
+		// numeric(8,2): nilai absolut harus di bawah 10^6 setelah dibulatkan ke 2 desimal
+		{"numeric max", columnConstraint{Precision: 8, Scale: 2}, 999999.99, ""},
+		{"numeric negative max", columnConstraint{Precision: 8, Scale: 2}, -999999.99, ""},
+		{"numeric limit", columnConstraint{Precision: 8, Scale: 2}, 1000000.0, "exceeds numeric(8,2)"},
+		{"numeric rounds up to limit", columnConstraint{Precision: 8, Scale: 2}, 999999.995, "exceeds numeric(8,2)"},
+		{"numeric rounds down", columnConstraint{Precision: 8, Scale: 2}, 999999.994, ""},
+		{"numeric extra decimals", columnConstraint{Precision: 8, Scale: 2}, 0.001, ""},
+		{"numeric pointer", columnConstraint{Precision: 8, Scale: 2}, number(12345678), "absolute value must be less than 1000000"},
+		{"numeric(18,2) price", columnConstraint{Precision: 18, Scale: 2}, 1234567890123.45, ""},
+		{"numeric(15,2) price", columnConstraint{Precision: 15, Scale: 2}, 1e13, "exceeds numeric(15,2)"},
+		{"numeric scale 0", columnConstraint{Precision: 3}, 999.4, ""},
+		{"numeric scale 0 rounds up", columnConstraint{Precision: 3}, 999.5, "exceeds numeric(3,0)"},
+		{"NaN", columnConstraint{Precision: 18, Scale: 2}, math.NaN(), "must be a finite number"},
+		{"infinity", columnConstraint{Precision: 18, Scale: 2}, math.Inf(1), "must be a finite number"},
+		{"negative infinity", columnConstraint{}, number(math.Inf(-1)), "must be a finite number"},
+
+		{"integer", columnConstraint{Integer: true}, 12.0, ""},
+		{"integer fraction", columnConstraint{Integer: true}, 12.5, "whole number"},
+		{"integer max", columnConstraint{Integer: true}, float64(math.MaxInt32), ""},
+		{"integer overflow", columnConstraint{Integer: true}, float64(math.MaxInt32) + 1, "whole number"},
+		{"integer min", columnConstraint{Integer: true}, float64(math.MinInt32), ""},
+		{"integer underflow", columnConstraint{Integer: true}, float64(math.MinInt32) - 1, "whole number"},
+
+		{"varchar max", columnConstraint{MaxLength: 5}, "abcde", ""},
+		{"varchar too long", columnConstraint{MaxLength: 5}, text("abcdef"), "exceeds 5 characters (got 6)"},
+		{"varchar counts characters", columnConstraint{MaxLength: 5}, "ééééé", ""},
+
+		{"not null nil", columnConstraint{NotNull: true}, nil, "must not be NULL"},
+		{"not null nil pointer", columnConstraint{NotNull: true}, (*string)(nil), "must not be NULL"},
+		{"nullable nil pointer", columnConstraint{MaxLength: 5}, (*string)(nil), ""},
+		{"not null value", columnConstraint{NotNull: true, MaxLength: 5}, "abc", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.constraint.check(tt.value)
+			if tt.want == "" && got != "" {
+				t.Errorf("check(%v) = %q, want valid", tt.value, got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("check(%v) = %q, want message containing %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMItemConstraints(t *testing.T) {
+	long, price, qty := strings.Repeat("X", 101), 1e16, 2.5
+	items := []MItem{
+		{ItemName: "GULA 1KG", PriceBase: 14000},
+		{ItemName: long, PriceBase: price, WholesaleMinQty: &qty},
+	}
+
+	errs := CheckMItemConstraints(items)
+	want := map[string]bool{"item_name": true, "price_base": true, "wholesale_min_qty": true}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %+v", len(errs), len(want), errs)
+	}
+	for _, err := range errs {
+		if err.Index != 1 || !want[err.Column] {
+			t.Errorf("unexpected error %+v", err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	LoaderInsert = "insert" // Multi-value INSERT per batch
+	LoaderCopy   = "copy"   // COPY FROM STDIN, jauh lebih cepat untuk import besar
+)
+
+// copyProgressRows jumlah baris di antara dua log progress saat COPY
+const copyProgressRows = 10000
+
+// copyColumnNames nama kolom tanpa quote, karena pq.CopyIn meng-quote nama kolom sendiri
+func copyColumnNames(columns []sqlColumn) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = strings.Trim(column.Name, `"`)
+	}
+	return names
+}
+
+// copyRows menulis semua rows dengan COPY di dalam satu transaction. Pada mode insert
+// data langsung di-COPY ke tabel tujuan; pada mode upsert (atau jika opts.RunID diisi)
+// data di-COPY ke tabel staging sementara lalu di-merge dengan query yang sama seperti
+// loader insert.
+func copyRows(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) error {
+	var key string
+	if opts.Mode == ModeUpsert {
+		var err error
+		key, err = t.upsertKey(opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if opts.prepare != nil {
+		if err := opts.prepare(tx); err != nil {
+			return err
+		}
+	}
+
+	// Upsert dan import yang dicatat untuk rollback butuh RETURNING, yang tidak tersedia
+	// pada COPY, sehingga data di-COPY ke tabel staging dulu lalu di-merge
+	staged := opts.Mode == ModeUpsert || opts.RunID > 0
+	target := t.Name
+	if staged {
+		target = "import_staging_" + t.Name
+		_, err = tx.Exec(fmt.Sprintf("CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA",
+			target, columnNames(t.Columns), t.Name))
+		if err != nil {
+			return fmt.Errorf("error creating staging table: %v", err)
+		}
+	}
+
+	if err := copyInto(tx, target, copyColumnNames(t.Columns), rows); err != nil {
+		return err
+	}
+
+	source := fmt.Sprintf("SELECT %s FROM %s", columnNames(t.Columns), target)
+	if opts.Mode == ModeUpsert {
+		var updated, inserted int
+		err = tx.QueryRow(buildUpsertFromSource(t.Name, t.Columns, key, source, opts.RunID)).Scan(&updated, &inserted)
+		if err != nil {
+			return fmt.Errorf("error merging staging table: %w", err)
+		}
+		log.Printf("Upsert from %s: %d updated, %d inserted", target, updated, inserted)
+	} else if staged {
+		var inserted int
+		err = tx.QueryRow(buildTrackedInsert(t.Name, t.Columns, source, opts.RunID)).Scan(&inserted)
+		if err != nil {
+			return fmt.Errorf("error merging staging table: %w", err)
+		}
+		log.Printf("Inserted from %s: %d rows", target, inserted)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
+
+// copyInto mengirim rows ke tabel target dengan COPY FROM STDIN dan mencatat progress
+func copyInto(tx *sql.Tx, target string, columns []string, rows [][]interface{}) error {
+	stmt, err := tx.Prepare(pq.CopyIn(target, columns...))
+	if err != nil {
+		return fmt.Errorf("error preparing copy: %v", err)
+	}
+
+	for i, row := range rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("error copying row %d: %w", i+1, err)
+		}
+		if (i+1)%copyProgressRows == 0 {
+			log.Printf("Copied %d/%d rows", i+1, len(rows))
+		}
+	}
+
+	// Exec tanpa argumen mengirim sisa buffer dan mengakhiri COPY
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("error executing copy: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("error closing copy: %v", err)
+	}
+	log.Printf("Copied %d/%d rows into %s", len(rows), len(rows), target)
+
+	return nil
+}
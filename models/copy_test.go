@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDSNEnv variabel environment berisi DSN PostgreSQL untuk test dan benchmark yang
+// membutuhkan database; kosong berarti test tersebut dilewati
+const testDSNEnv = "EXCEL_SEEDER_TEST_DSN"
+
+func TestCopyColumnNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []sqlColumn
+		want    []string
+	}{
+		{"plain", []sqlColumn{{"m_bu_id", "int8"}, {"item_name", "varchar"}}, []string{"m_bu_id", "item_name"}},
+		{"quoted", []sqlColumn{{`"name"`, "varchar"}, {`"group"`, "varchar"}}, []string{"name", "group"}},
+		{"empty", nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := copyColumnNames(tt.columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("copyColumnNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMItemRowsCopyEncoding memastikan setiap nilai baris yang dikirim ke COPY bisa diubah
+// menjadi driver.Value: pointer nil menjadi NULL dan pointer terisi menjadi nilainya
+func TestMItemRowsCopyEncoding(t *testing.T) {
+	barcode, qty := "8998866200301", 12.0
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	item := MItem{
+		ItemName:        "INDOMIE GORENG 85G",
+		PriceBase:       2500.5,
+		IsActive:        true,
+		Barcode:         &barcode,
+		WholesaleMinQty: &qty,
+		CreatedAt:       &created,
+	}
+
+	rows := mItemRows([]MItem{item})
+	if len(rows) != 1 || len(rows[0]) != len(mItemColumns) {
+		t.Fatalf("row has %d values, want %d (one per column)", len(rows[0]), len(mItemColumns))
+	}
+
+	want := map[string]driver.Value{
+		"item_name":          "INDOMIE GORENG 85G",
+		"price_base":         2500.5,
+		"is_active":          true,
+		"barcode":            "8998866200301",
+		"wholesale_min_qty":  12.0,
+		"created_at":         created,
+		"default_price_sale": nil,
+		"m_supp_id":          nil,
+	}
+	for column, expected := range want {
+		t.Run(column, func(t *testing.T) {
+			index := columnIndex(mItemColumns[:], column)
+			got, err := driver.DefaultParameterConverter.ConvertValue(rows[0][index])
+			if err != nil {
+				t.Fatalf("ConvertValue(%s): %v", column, err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("%s = %#v, want %#v", column, got, expected)
+			}
+		})
+	}
+}
+
+// benchmarkItems membuat n item dengan nilai yang berbeda per baris
+func benchmarkItems(n int) []MItem {
+	items := make([]MItem, n)
+	for i := range items {
+		barcode, name := fmt.Sprintf("BENCH%08d", i), fmt.Sprintf("BENCH ITEM %d", i)
+		price := float64(i%1000) + 0.5
+		items[i] = MItem{ItemName: name, Barcode: &barcode, PriceBase: price, DefaultPriceSale: &price, IsActive: true}
+	}
+	return items
+}
+
+// benchmarkTable membuat tabel dengan kolom m_item untuk benchmark, dan menghapusnya setelah selesai
+func benchmarkTable(b *testing.B) (*sql.DB, table) {
+	b.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s not set, skipping database benchmark", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatalf("error opening database: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	t := table{Name: "bench_m_item", Columns: mItemColumns[:]}
+	definitions := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		definitions[i] = column.Name + " " + column.Type
+	}
+	if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s; CREATE TABLE %s (id bigserial PRIMARY KEY, %s)",
+		t.Name, t.Name, strings.Join(definitions, ", "))); err != nil {
+		b.Fatalf("error creating benchmark table: %v", err)
+	}
+	b.Cleanup(func() { db.Exec("DROP TABLE IF EXISTS " + t.Name) })
+	return db, t
+}
+
+// benchmarkLoader mengukur waktu insert 10000 item dengan loader tertentu. Dengan tracked
+// setiap baris dicatat ke import_run_rows seperti import dengan -history.
+func benchmarkLoader(b *testing.B, loader string, tracked bool) {
+	db, t := benchmarkTable(b)
+	rows := mItemRows(benchmarkItems(10000))
+	opts := InsertOptions{Loader: loader}
+	if tracked {
+		run := &ImportRun{FileName: "benchmark.xlsx", FileSHA256: "benchmark", Entity: "item", Profile: "benchmark", Mode: ModeInsert, Loader: loader}
+		if err := StartImportRun(context.Background(), db, run); err != nil {
+			b.Fatalf("error starting import run: %v", err)
+		}
+		b.Cleanup(func() { db.Exec("DELETE FROM import_runs WHERE id = $1", run.ID) })
+		opts.RunID = run.ID
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		if _, err := db.Exec("TRUNCATE " + t.Name); err != nil {
+			b.Fatalf("error truncating benchmark table: %v", err)
+		}
+		if tracked {
+			if _, err := db.Exec("DELETE FROM import_run_rows WHERE run_id = $1", opts.RunID); err != nil {
+				b.Fatalf("error clearing tracked rows: %v", err)
+			}
+		}
+		b.StartTimer()
+		if err := insertRows(context.Background(), db, t, rows, opts); err != nil {
+			b.Fatalf("insert with %s loader: %v", loader, err)
+		}
+	}
+	b.ReportMetric(float64(len(rows)*b.N)/b.Elapsed().Seconds(), "rows/s")
+}
+
+func BenchmarkInsertCopy(b *testing.B) {
+	benchmarkLoader(b, LoaderCopy, false)
+}
+
+func BenchmarkInsertMultiValue(b *testing.B) {
+	benchmarkLoader(b, LoaderInsert, false)
+}
+
+// BenchmarkInsertCopyTracked mengukur COPY lewat tabel staging yang dipakai saat -history aktif
+func BenchmarkInsertCopyTracked(b *testing.B) {
+	benchmarkLoader(b, LoaderCopy, true)
+}
+
+func BenchmarkInsertMultiValueTracked(b *testing.B) {
+	benchmarkLoader(b, LoaderInsert, true)
+}
@@ -1,10 +1,10 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"reflect"
 	"strings"
 	"time"
 )
@@ -49,290 +49,210 @@ type MItem struct {
 	WholesaleUnitPrice  *float64   `db:"wholesale_unit_price"`
 	Wholesale2MinQty    *float64   `db:"wholesale_2_min_qty"`
 	Wholesale2UnitPrice *float64   `db:"wholesale_2_unit_price"`
-}
+	Wholesale3MinQty    *float64   `db:"wholesale_3_min_qty"`
+	Wholesale3UnitPrice *float64   `db:"wholesale_3_unit_price"`
 
-const (
-	PostgreSQLParamLimit = 32767 // PostgreSQL parameter limit adalah 65535, tapi kita gunakan 32767 untuk safety
-	MItemColumnCount     = 34    // Jumlah kolom dalam tabel m_item (tanpa id yang auto-increment)
-)
+	// Field berikut tidak disimpan ke m_item, hanya dipakai selama proses import
+	SupplierName *string `db:"-"` // Kode atau nama supplier dari Excel, di-resolve ke MSuppID
+	SourceSheet  string  `db:"-"` // Nama sheet asal baris, untuk pesan error pada import multi-sheet
+	SourceRow    int     `db:"-"` // Nomor baris di Excel (1-based), untuk pesan error
+}
 
-func calculateBatchSize() int {
-	return PostgreSQLParamLimit / MItemColumnCount
+// CheckMItemConstraints memeriksa panjang teks, presisi angka dan kolom NOT NULL sesuai
+// definisi m_item, sehingga baris yang pasti gagal di database bisa ditolak sebelum insert
+func CheckMItemConstraints(items []MItem) []RowError {
+	return checkConstraints(mItemTable, mItemRows(items))
 }
 
-func InsertMItems(db *sql.DB, items []MItem) error {
-	if len(items) == 0 {
-		return nil
-	}
+// DryRunMItems menjalankan seluruh batch insert item di dalam satu transaction lalu
+// me-rollback-nya, dan mengembalikan baris yang ditolak database
+func DryRunMItems(ctx context.Context, db *sql.DB, items []MItem, opts InsertOptions) ([]RowError, error) {
+	rows := mItemRows(items)
+	opts.prepare = itemSupplierPreparer(items, rows, opts.RunID)
+	return dryRunRows(ctx, db, mItemTable, rows, opts)
+}
 
-	batchSize := calculateBatchSize()
-	log.Printf("Using batch size: %d (calculated from %d/%d)", batchSize, PostgreSQLParamLimit, MItemColumnCount)
+// sqlColumn nama kolom beserta tipe PostgreSQL-nya, dipakai untuk cast nilai pada query upsert
+type sqlColumn struct {
+	Name string
+	Type string
+}
 
-	for i := 0; i < len(items); i += batchSize {
-		end := i + batchSize
-		if end > len(items) {
-			end = len(items)
-		}
+// mItemColumns daftar kolom m_item yang ditulis saat insert (tanpa id yang auto-increment).
+// Nilai setiap kolom diambil dari field MItem dengan tag db yang sama.
+var mItemColumns = [...]sqlColumn{
+	{"m_bu_id", "int8"}, {"code", "varchar"}, {"m_item_type_id", "int8"},
+	{"m_cat1_id", "int8"}, {"m_cat2_id", "int8"}, {"m_cat3_id", "int8"}, {"m_cat4_id", "int8"},
+	{"item_name", "varchar"}, {"item_name_long", "text"}, {"unit_id", "int8"}, {"unit", "varchar"},
+	{"mnfct", "varchar"}, {"price_base", "numeric"}, {"item_photo", "varchar"}, {"spec", "varchar"},
+	{"weight", "numeric"}, {"weight_unit_id", "int8"}, {"dim_l", "float8"}, {"dim_l_unit_id", "int8"},
+	{"dim_p", "float8"}, {"dim_p_unit_id", "int8"}, {"dim_t", "float8"}, {"dim_t_unit_id", "int8"},
+	{"is_active", "bool"}, {"creator_id", "int4"}, {"editor_id", "int4"}, {"created_at", "timestamp"},
+	{"updated_at", "timestamp"}, {"is_timbangan", "bool"}, {"round", "numeric"}, {"flag_ppn", "bool"},
+	{"m_supp_id", "int8"}, {"default_price_sale", "numeric"}, {"barcode", "varchar"},
+	{"wholesale_min_qty", "int4"}, {"wholesale_unit_price", "numeric"},
+	{"wholesale_2_min_qty", "int4"}, {"wholesale_2_unit_price", "numeric"},
+	{"wholesale_3_min_qty", "int4"}, {"wholesale_3_unit_price", "numeric"},
+}
 
-		batch := items[i:end]
-		if err := insertBatch(db, batch); err != nil {
-			return fmt.Errorf("error inserting batch %d-%d: %v", i+1, end, err)
+// columnIndex mengembalikan posisi kolom berdasarkan nama, atau -1 jika tidak ada
+func columnIndex(columns []sqlColumn, name string) int {
+	for i, column := range columns {
+		if column.Name == name {
+			return i
 		}
-		log.Printf("Successfully inserted batch %d-%d (%d items)", i+1, end, len(batch))
 	}
-
-	return nil
+	return -1
 }
 
-// insertBatch melakukan insert untuk satu batch menggunakan multi-value INSERT
-func insertBatch(db *sql.DB, items []MItem) error {
-	if len(items) == 0 {
-		return nil
-	}
-
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("error starting transaction: %v", err)
+// columnNames menggabungkan nama kolom untuk dipakai di statement INSERT
+func columnNames(columns []sqlColumn) string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
 	}
-	defer tx.Rollback()
-
-	// Build multi-value INSERT query
-	query := `INSERT INTO m_item (
-		m_bu_id, code, m_item_type_id, m_cat1_id, m_cat2_id, m_cat3_id, m_cat4_id,
-		item_name, item_name_long, unit_id, unit, mnfct, price_base, item_photo,
-		spec, weight, weight_unit_id, dim_l, dim_l_unit_id, dim_p, dim_p_unit_id,
-		dim_t, dim_t_unit_id, is_active, creator_id, editor_id, created_at,
-		updated_at, is_timbangan, round, flag_ppn, m_supp_id, default_price_sale, barcode
-	) VALUES `
-
-	valuesPlaceholders := make([]string, len(items))
-	args := make([]interface{}, 0, len(items)*MItemColumnCount)
+	return strings.Join(names, ", ")
+}
 
-	for i, item := range items {
-		paramStart := i * MItemColumnCount
-		placeholders := make([]string, MItemColumnCount)
-		for j := 0; j < MItemColumnCount; j++ {
-			placeholders[j] = fmt.Sprintf("$%d", paramStart+j+1)
+// columnFields mengembalikan index field struct record (lewat tag db) untuk setiap kolom.
+// Panic jika ada kolom tanpa field, sehingga daftar kolom dan struct tidak bisa berbeda diam-diam.
+func columnFields(record interface{}, columns []sqlColumn) []int {
+	t := reflect.TypeOf(record)
+	fields := make([]int, len(columns))
+	for i, column := range columns {
+		fields[i] = -1
+		for j := 0; j < t.NumField(); j++ {
+			if t.Field(j).Tag.Get("db") == strings.Trim(column.Name, `"`) {
+				fields[i] = j
+				break
+			}
+		}
+		if fields[i] < 0 {
+			panic(fmt.Sprintf("models: %s has no field for column %s", t.Name(), column.Name))
 		}
-		valuesPlaceholders[i] = "(" + strings.Join(placeholders, ", ") + ")"
-
-		// Add arguments in the same order as the columns
-		args = append(args,
-			item.MBuID, item.Code, item.MItemTypeID, item.MCat1ID, item.MCat2ID,
-			item.MCat3ID, item.MCat4ID, item.ItemName, item.ItemNameLong,
-			item.UnitID, item.Unit, item.Mnfct, item.PriceBase, item.ItemPhoto,
-			item.Spec, item.Weight, item.WeightUnitID, item.DimL, item.DimLUnitID,
-			item.DimP, item.DimPUnitID, item.DimT, item.DimTUnitID, item.IsActive,
-			item.CreatorID, item.EditorID, item.CreatedAt, item.UpdatedAt,
-			item.IsTimbangan, item.Round, item.FlagPPN, item.MSuppID,
-			item.DefaultPriceSale, item.Barcode,
-		)
 	}
+	return fields
+}
 
-	query += strings.Join(valuesPlaceholders, ", ")
-
-	_, err = tx.Exec(query, args...)
-	if err != nil {
-		return fmt.Errorf("error executing batch insert: %v", err)
+// fieldValues mengembalikan nilai field record sesuai urutan fields
+func fieldValues(record reflect.Value, fields []int) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i] = record.Field(field).Interface()
 	}
+	return values
+}
 
-	err = tx.Commit()
-	if err != nil {
-		return fmt.Errorf("error committing transaction: %v", err)
+// fieldPointers mengembalikan pointer ke field record sesuai urutan fields, untuk rows.Scan
+func fieldPointers(record reflect.Value, fields []int) []interface{} {
+	pointers := make([]interface{}, len(fields))
+	for i, field := range fields {
+		pointers[i] = record.Field(field).Addr().Interface()
 	}
-
-	return nil
+	return pointers
 }
 
-// GenerateSeederSQL membuat file SQL seeder dari data items
-func GenerateSeederSQL(items []MItem, outputPath string) error {
-	if len(items) == 0 {
-		return fmt.Errorf("no items to generate seeder")
-	}
+// mItemFields index field MItem untuk setiap kolom di mItemColumns
+var mItemFields = columnFields(MItem{}, mItemColumns[:])
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("error creating seeder file: %v", err)
-	}
-	defer file.Close()
+// columnValues mengembalikan nilai item sesuai urutan mItemColumns
+func (item MItem) columnValues() []interface{} {
+	return fieldValues(reflect.ValueOf(item), mItemFields)
+}
 
-	// Write header
-	_, err = file.WriteString("-- Generated seeder file for m_item table\n")
-	if err != nil {
-		return err
-	}
-	_, err = file.WriteString(fmt.Sprintf("-- Generated at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
-	if err != nil {
-		return err
-	}
-	_, err = file.WriteString(fmt.Sprintf("-- Total items: %d\n\n", len(items)))
-	if err != nil {
-		return err
-	}
+// scanTargets mengembalikan pointer ke field item sesuai urutan id lalu mItemColumns,
+// untuk rows.Scan hasil SELECT dari m_item
+func (item *MItem) scanTargets() []interface{} {
+	return append([]interface{}{&item.ID}, fieldPointers(reflect.ValueOf(item).Elem(), mItemFields)...)
+}
 
-	batchSize := calculateBatchSize()
-	log.Printf("Generating SQL seeder with batch size: %d", batchSize)
+const (
+	PostgreSQLParamLimit = 32767             // PostgreSQL parameter limit adalah 65535, tapi kita gunakan 32767 untuk safety
+	MItemColumnCount     = len(mItemColumns) // Jumlah kolom dalam tabel m_item (tanpa id yang auto-increment)
+)
 
-	for i := 0; i < len(items); i += batchSize {
-		end := i + batchSize
-		if end > len(items) {
-			end = len(items)
-		}
+// mItemSuppIDColumn index kolom m_supp_id di mItemColumns
+var mItemSuppIDColumn = columnIndex(mItemColumns[:], "m_supp_id")
+
+// mItemTable definisi tabel m_item untuk batch insert dan seeder
+var mItemTable = table{
+	Name:    "m_item",
+	Columns: mItemColumns[:],
+	Constraints: map[string]columnConstraint{
+		"code":                   {MaxLength: 50},
+		"item_name":              {MaxLength: 100, NotNull: true},
+		"unit":                   {MaxLength: 100},
+		"mnfct":                  {MaxLength: 100},
+		"price_base":             {Precision: 18, Scale: 2, NotNull: true},
+		"item_photo":             {MaxLength: 255},
+		"spec":                   {MaxLength: 100},
+		"weight":                 {Precision: 8, Scale: 2},
+		"round":                  {Precision: 10, Scale: 2},
+		"default_price_sale":     {Precision: 18, Scale: 2},
+		"barcode":                {MaxLength: 255},
+		"wholesale_min_qty":      {Integer: true},
+		"wholesale_unit_price":   {Precision: 15, Scale: 2},
+		"wholesale_2_min_qty":    {Integer: true},
+		"wholesale_2_unit_price": {Precision: 15, Scale: 2},
+		"wholesale_3_min_qty":    {Integer: true},
+		"wholesale_3_unit_price": {Precision: 15, Scale: 2},
+	},
+	UpsertKeys: map[string]string{
+		"barcode": "barcode",
+		"code":    "code",
+	},
+	DefaultUpsertKey: "barcode",
+	ForeignKeys: map[string]string{
+		"m_supp_id": "m_supp",
+	},
+	Defaults: map[string]interface{}{
+		"wholesale_min_qty":      0.0,
+		"wholesale_unit_price":   0.0,
+		"wholesale_2_min_qty":    0.0,
+		"wholesale_2_unit_price": 0.0,
+		"wholesale_3_min_qty":    0.0,
+		"wholesale_3_unit_price": 0.0,
+	},
+}
 
-		batch := items[i:end]
-		if err := writeBatchSQL(file, batch, i+1); err != nil {
-			return fmt.Errorf("error writing batch %d-%d: %v", i+1, end, err)
-		}
+// mItemRows mengubah items menjadi baris nilai sesuai urutan mItemColumns, dengan nilai
+// kosong diganti mItemTable.Defaults
+func mItemRows(items []MItem) [][]interface{} {
+	rows := make([][]interface{}, len(items))
+	for i, item := range items {
+		rows[i] = mItemTable.withDefaults(item.columnValues())
 	}
+	return rows
+}
 
-	log.Printf("Successfully generated seeder file: %s", outputPath)
-	return nil
+// InsertMItems melakukan batch insert (atau upsert) data item ke tabel m_item. Supplier item
+// yang belum ada di m_supp dibuat lebih dulu, di transaction yang sama jika loader-nya memakai
+// satu transaction.
+func InsertMItems(ctx context.Context, db *sql.DB, items []MItem, opts InsertOptions) error {
+	rows := mItemRows(items)
+	opts.prepare = itemSupplierPreparer(items, rows, opts.RunID)
+	return insertRows(ctx, db, mItemTable, rows, opts)
 }
 
-// writeBatchSQL menulis satu batch INSERT statement ke file
-func writeBatchSQL(file *os.File, items []MItem, batchNum int) error {
+// GenerateSeederSQL membuat file SQL seeder dari data items.
+// Item yang membawa SupplierName di-resolve ke m_supp_id lewat subquery saat seeder dijalankan.
+func GenerateSeederSQL(items []MItem, outputPath string, opts InsertOptions) error {
 	if len(items) == 0 {
-		return nil
-	}
-
-	// Write batch comment
-	_, err := file.WriteString(fmt.Sprintf("-- Batch %d (%d items)\n", batchNum, len(items)))
-	if err != nil {
-		return err
-	}
-
-	// Write INSERT statement
-	_, err = file.WriteString(`INSERT INTO m_item (
-	m_bu_id, code, m_item_type_id, m_cat1_id, m_cat2_id, m_cat3_id, m_cat4_id,
-	item_name, item_name_long, unit_id, unit, mnfct, price_base, item_photo,
-	spec, weight, weight_unit_id, dim_l, dim_l_unit_id, dim_p, dim_p_unit_id,
-	dim_t, dim_t_unit_id, is_active, creator_id, editor_id, created_at,
-	updated_at, is_timbangan, round, flag_ppn, m_supp_id, default_price_sale, barcode
-) VALUES`)
-	if err != nil {
-		return err
-	}
-
-	for i, item := range items {
-		if i > 0 {
-			_, err = file.WriteString(",")
-			if err != nil {
-				return err
-			}
-		}
-
-		_, err = file.WriteString("\n\t(")
-		if err != nil {
-			return err
-		}
-
-		// Format values
-		values := []string{
-			formatSQLValue(item.MBuID),
-			formatSQLValue(item.Code),
-			formatSQLValue(item.MItemTypeID),
-			formatSQLValue(item.MCat1ID),
-			formatSQLValue(item.MCat2ID),
-			formatSQLValue(item.MCat3ID),
-			formatSQLValue(item.MCat4ID),
-			formatSQLValue(item.ItemName),
-			formatSQLValue(item.ItemNameLong),
-			formatSQLValue(item.UnitID),
-			formatSQLValue(item.Unit),
-			formatSQLValue(item.Mnfct),
-			formatSQLValue(item.PriceBase),
-			formatSQLValue(item.ItemPhoto),
-			formatSQLValue(item.Spec),
-			formatSQLValue(item.Weight),
-			formatSQLValue(item.WeightUnitID),
-			formatSQLValue(item.DimL),
-			formatSQLValue(item.DimLUnitID),
-			formatSQLValue(item.DimP),
-			formatSQLValue(item.DimPUnitID),
-			formatSQLValue(item.DimT),
-			formatSQLValue(item.DimTUnitID),
-			formatSQLValue(item.IsActive),
-			formatSQLValue(item.CreatorID),
-			formatSQLValue(item.EditorID),
-			formatSQLValue(item.CreatedAt),
-			formatSQLValue(item.UpdatedAt),
-			formatSQLValue(item.IsTimbangan),
-			formatSQLValue(item.Round),
-			formatSQLValue(item.FlagPPN),
-			formatSQLValue(item.MSuppID),
-			formatSQLValue(item.DefaultPriceSale),
-			formatSQLValue(item.Barcode),
-		}
-
-		_, err = file.WriteString(strings.Join(values, ", "))
-		if err != nil {
-			return err
-		}
-
-		_, err = file.WriteString(")")
-		if err != nil {
-			return err
-		}
+		return fmt.Errorf("no items to generate seeder")
 	}
 
-	_, err = file.WriteString(";\n\n")
-	return err
+	preamble := supplierSeederPreamble(items, opts.MissingSupplier)
+	return generateSeeder(mItemTable, mItemSeederRows(items), outputPath, preamble, opts)
 }
 
-// formatSQLValue memformat nilai untuk SQL statement
-func formatSQLValue(value interface{}) string {
-	switch v := value.(type) {
-	case nil:
-		return "NULL"
-	case *string:
-		if v == nil {
-			return "NULL"
-		}
-		return fmt.Sprintf("'%s'", strings.ReplaceAll(*v, "'", "''"))
-	case string:
-		return fmt.Sprintf("'%s'", strings.ReplaceAll(v, "'", "''"))
-	case *int64:
-		if v == nil {
-			return "NULL"
-		}
-		return fmt.Sprintf("%d", *v)
-	case int64:
-		return fmt.Sprintf("%d", v)
-	case *int32:
-		if v == nil {
-			return "NULL"
-		}
-		return fmt.Sprintf("%d", *v)
-	case int32:
-		return fmt.Sprintf("%d", v)
-	case *float64:
-		if v == nil {
-			return "NULL"
-		}
-		return fmt.Sprintf("%f", *v)
-	case float64:
-		return fmt.Sprintf("%f", v)
-	case *bool:
-		if v == nil {
-			return "NULL"
-		}
-		if *v {
-			return "true"
-		}
-		return "false"
-	case bool:
-		if v {
-			return "true"
-		}
-		return "false"
-	case *time.Time:
-		if v == nil {
-			return "NULL"
+// mItemSeederRows seperti mItemRows, tetapi m_supp_id item yang membawa SupplierName
+// diganti subquery pencarian supplier
+func mItemSeederRows(items []MItem) [][]interface{} {
+	rows := mItemRows(items)
+	for i, item := range items {
+		if item.MSuppID == nil && item.SupplierName != nil {
+			rows[i][mItemSuppIDColumn] = supplierLookupExpr(*item.SupplierName)
 		}
-		return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05"))
-	case time.Time:
-		return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05"))
-	default:
-		return "NULL"
 	}
+	return rows
 }
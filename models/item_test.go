@@ -0,0 +1,91 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestScanTargetsMatchColumnValues memastikan scanTargets (setelah id) dan columnValues
+// menunjuk ke field yang sama untuk setiap kolom, sehingga data yang dibaca dari database
+// ditulis kembali ke kolom yang sama
+func TestScanTargetsMatchColumnValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []sqlColumn
+		targets func(i int) ([]interface{}, []interface{})
+	}{
+		{"m_item", mItemColumns[:], func(i int) ([]interface{}, []interface{}) {
+			var item MItem
+			targets := item.scanTargets()
+			setNonZero(targets[i+1])
+			return targets, item.columnValues()
+		}},
+		{"m_supp", mSuppColumns[:], func(i int) ([]interface{}, []interface{}) {
+			var supp MSupp
+			targets := supp.scanTargets()
+			setNonZero(targets[i+1])
+			return targets, supp.columnValues()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, column := range tt.columns {
+				targets, values := tt.targets(i)
+				if len(targets) != len(tt.columns)+1 || len(values) != len(tt.columns) {
+					t.Fatalf("%d scan targets and %d values, want %d and %d", len(targets), len(values), len(tt.columns)+1, len(tt.columns))
+				}
+				for j, value := range values {
+					if set := !reflect.ValueOf(value).IsZero(); set != (i == j) {
+						t.Errorf("scanning %s changed value %d (%s): %v", column.Name, j, tt.columns[j].Name, set)
+					}
+				}
+			}
+		})
+	}
+}
+
+// setNonZero mengisi pointer hasil scanTargets dengan nilai yang bukan zero value
+func setNonZero(target interface{}) {
+	v := reflect.ValueOf(target).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Bool:
+		v.SetBool(true)
+	}
+}
+
+func TestColumnFieldsPanicsOnUnknownColumn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("columnFields() did not panic for a column without field")
+		}
+	}()
+	columnFields(MItem{}, []sqlColumn{{"not_a_column", "int8"}})
+}
+
+// TestMItemRowsDefaults memastikan tingkat harga grosir yang kosong ditulis sebagai 0 sesuai
+// DEFAULT di migration, bukan NULL eksplisit
+func TestMItemRowsDefaults(t *testing.T) {
+	qty, price := 3.0, 53500.0
+	rows := mItemRows([]MItem{{ItemName: "GULA", WholesaleMinQty: &qty, WholesaleUnitPrice: &price}})
+
+	want := map[string]interface{}{
+		"wholesale_min_qty":      &qty,
+		"wholesale_unit_price":   &price,
+		"wholesale_2_min_qty":    0.0,
+		"wholesale_2_unit_price": 0.0,
+		"wholesale_3_min_qty":    0.0,
+		"wholesale_3_unit_price": 0.0,
+		"default_price_sale":     (*float64)(nil),
+	}
+	for column, expected := range want {
+		if got := rows[0][columnIndex(mItemColumns[:], column)]; !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s = %#v, want %#v", column, got, expected)
+		}
+	}
+}
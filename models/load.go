@@ -0,0 +1,86 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// LoadFilter membatasi baris yang dibaca oleh LoadMItems dan LoadMSupps
+type LoadFilter struct {
+	ActiveOnly bool // Hanya baris dengan is_active = true
+}
+
+// LoadMItems membaca seluruh m_item berurutan sesuai id. SupplierName diisi dengan kode
+// (atau nama jika kode kosong) supplier dari m_supp, sehingga hasilnya bisa diekspor
+// kembali ke format workbook import.
+func LoadMItems(ctx context.Context, db *sql.DB, filter LoadFilter) ([]MItem, error) {
+	query := fmt.Sprintf(`SELECT %s, COALESCE(s.code, s."name") FROM %s t
+		LEFT JOIN m_supp s ON s.id = t.m_supp_id%s ORDER BY t.id`,
+		selectColumns(mItemTable), mItemTable.Name, filter.where())
+
+	var items []MItem
+	err := loadRows(ctx, db, mItemTable, query, func(rows *sql.Rows) error {
+		var item MItem
+		if err := rows.Scan(append(item.scanTargets(), &item.SupplierName)...); err != nil {
+			return err
+		}
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// LoadMSupps membaca seluruh m_supp berurutan sesuai id
+func LoadMSupps(ctx context.Context, db *sql.DB, filter LoadFilter) ([]MSupp, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s t%s ORDER BY t.id", selectColumns(mSuppTable), mSuppTable.Name, filter.where())
+
+	var supps []MSupp
+	err := loadRows(ctx, db, mSuppTable, query, func(rows *sql.Rows) error {
+		var supp MSupp
+		if err := rows.Scan(supp.scanTargets()...); err != nil {
+			return err
+		}
+		supps = append(supps, supp)
+		return nil
+	})
+	return supps, err
+}
+
+// where menyusun klausa WHERE untuk tabel dengan alias t
+func (f LoadFilter) where() string {
+	if f.ActiveOnly {
+		return " WHERE t.is_active"
+	}
+	return ""
+}
+
+// selectColumns daftar kolom tabel dengan alias t, diawali id
+func selectColumns(t table) string {
+	names := make([]string, 0, len(t.Columns)+1)
+	names = append(names, "t.id")
+	for _, column := range t.Columns {
+		names = append(names, "t."+column.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// loadRows menjalankan query dan memanggil scan untuk setiap baris hasilnya
+func loadRows(ctx context.Context, db *sql.DB, t table, query string, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("error querying %s: %v", t.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return fmt.Errorf("error scanning %s: %v", t.Name, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %v", t.Name, err)
+	}
+	return nil
+}
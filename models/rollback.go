@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	RunRowInsert = "insert" // Baris baru; rollback menghapusnya
+	RunRowUpdate = "update" // Baris lama yang diubah; rollback mengembalikan before_image
+)
+
+// rollbackTables tabel yang dicatat di import_run_rows, berurutan sesuai urutan rollback
+// (m_item lebih dulu karena mereferensikan m_supp)
+var rollbackTables = []table{mItemTable, mSuppTable}
+
+// RollbackOptions mengatur perilaku RollbackImportRun
+type RollbackOptions struct {
+	// DryRun menjalankan rollback di dalam transaction lalu membatalkannya,
+	// hanya untuk melihat jumlah baris yang akan dihapus dan dikembalikan
+	DryRun bool
+
+	// Force tetap menjalankan rollback walaupun run masih berstatus running atau
+	// barisnya sudah diubah lagi oleh run yang lebih baru
+	Force bool
+}
+
+// RollbackResult jumlah baris yang dihapus dan dikembalikan per tabel
+type RollbackResult struct {
+	Run      ImportRun
+	Deleted  map[string]int // Nama tabel -> baris yang di-insert run lalu dihapus
+	Restored map[string]int // Nama tabel -> baris yang di-update run lalu dikembalikan
+}
+
+// RollbackImportRun membatalkan perubahan satu import run dalam satu transaction: baris
+// yang di-insert run dihapus dan baris yang di-update dikembalikan ke before-image yang
+// dicatat saat import. Run ditandai rolled_back_at sehingga tidak bisa di-rollback dua kali.
+func RollbackImportRun(ctx context.Context, db *sql.DB, runID int64, opts RollbackOptions) (*RollbackResult, error) {
+	if _, err := db.ExecContext(ctx, importRunsDDL); err != nil {
+		return nil, fmt.Errorf("error creating import_runs table: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result := &RollbackResult{Deleted: map[string]int{}, Restored: map[string]int{}}
+	run := &result.Run
+	var rolledBackAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `SELECT id, file_name, entity, mode, outcome, started_at, rolled_back_at
+		FROM import_runs WHERE id = $1 FOR UPDATE`, runID,
+	).Scan(&run.ID, &run.FileName, &run.Entity, &run.Mode, &run.Outcome, &run.StartedAt, &rolledBackAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("import run %d not found", runID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading import run %d: %v", runID, err)
+	}
+	if rolledBackAt.Valid {
+		return nil, fmt.Errorf("import run %d was already rolled back at %s", runID, rolledBackAt.Time.Format(time.DateTime))
+	}
+	if run.Outcome == RunRunning && !opts.Force {
+		return nil, fmt.Errorf("import run %d is still marked as running; wait for it to finish or use force if the process died", runID)
+	}
+
+	for _, t := range rollbackTables {
+		later, err := laterRunRows(ctx, tx, t, runID)
+		if err != nil {
+			return nil, err
+		}
+		if later > 0 {
+			if !opts.Force {
+				return nil, fmt.Errorf("%d %s rows of run %d were changed again by later runs; roll those back first or use force", later, t.Name, runID)
+			}
+			log.Printf("Warning: %d %s rows were changed again by later runs and will be overwritten", later, t.Name)
+		}
+
+		restored, err := restoreRunRows(ctx, tx, t, runID)
+		if err != nil {
+			return nil, err
+		}
+		deleted, err := deleteRunRows(ctx, tx, t, runID)
+		if err != nil {
+			return nil, err
+		}
+		if restored > 0 || deleted > 0 {
+			log.Printf("Rollback %s: %d rows deleted, %d rows restored", t.Name, deleted, restored)
+		}
+		result.Deleted[t.Name] = deleted
+		result.Restored[t.Name] = restored
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE import_runs SET rolled_back_at = $1 WHERE id = $2", time.Now(), runID); err != nil {
+		return nil, fmt.Errorf("error marking import run %d as rolled back: %v", runID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return result, nil
+}
+
+// laterRunRows menghitung baris run yang juga diubah oleh run lebih baru yang belum di-rollback
+func laterRunRows(ctx context.Context, tx *sql.Tx, t table, runID int64) (int, error) {
+	var count int
+	err := tx.QueryRowContext(ctx, `SELECT count(DISTINCT r.row_id) FROM import_run_rows r
+		JOIN import_run_rows l ON l.table_name = r.table_name AND l.row_id = r.row_id AND l.run_id > r.run_id
+		JOIN import_runs lr ON lr.id = l.run_id AND lr.rolled_back_at IS NULL
+		WHERE r.run_id = $1 AND r.table_name = $2`, runID, t.Name,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error checking later runs on %s: %v", t.Name, err)
+	}
+	return count, nil
+}
+
+// restoreRunRows mengembalikan baris yang di-update run ke before-image paling awal.
+// Baris yang juga di-insert oleh run yang sama dilewati karena akan dihapus.
+func restoreRunRows(ctx context.Context, tx *sql.Tx, t table, runID int64) (int, error) {
+	assignments := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		assignments[i] = fmt.Sprintf("%s = b.%s", column.Name, column.Name)
+	}
+
+	query := fmt.Sprintf(`UPDATE %s t SET
+		%s
+	FROM (
+		SELECT DISTINCT ON (row_id) row_id, before_image FROM import_run_rows
+		WHERE run_id = $1 AND table_name = $2 AND "action" = $3
+		ORDER BY row_id, id
+	) r, jsonb_populate_record(NULL::%s, r.before_image) b
+	WHERE t.id = r.row_id AND NOT EXISTS (
+		SELECT 1 FROM import_run_rows i
+		WHERE i.run_id = $1 AND i.table_name = $2 AND i."action" = $4 AND i.row_id = r.row_id
+	)`, t.Name, strings.Join(assignments, ",\n\t\t"), t.Name)
+
+	result, err := tx.ExecContext(ctx, query, runID, t.Name, RunRowUpdate, RunRowInsert)
+	if err != nil {
+		return 0, fmt.Errorf("error restoring updated %s rows: %v", t.Name, err)
+	}
+	restored, _ := result.RowsAffected()
+	return int(restored), nil
+}
+
+// deleteRunRows menghapus baris yang di-insert run
+func deleteRunRows(ctx context.Context, tx *sql.Tx, t table, runID int64) (int, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id IN (
+		SELECT row_id FROM import_run_rows WHERE run_id = $1 AND table_name = $2 AND "action" = $3
+	)`, t.Name)
+
+	result, err := tx.ExecContext(ctx, query, runID, t.Name, RunRowInsert)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting inserted %s rows: %v", t.Name, err)
+	}
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
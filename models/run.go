@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	RunRunning     = "running"     // Import sedang berjalan (atau proses mati tanpa sempat mencatat hasil)
+	RunSuccess     = "success"     // Semua baris valid berhasil ditulis
+	RunFailed      = "failed"      // Import berhenti karena error
+	RunInterrupted = "interrupted" // Import dihentikan dengan SIGINT/SIGTERM
+)
+
+// importRunsDDL definisi tabel riwayat import dan baris yang diubah setiap run (untuk
+// rollback), dibuat otomatis jika belum ada. Sama dengan db/import_runs_migration.sql.
+const importRunsDDL = `CREATE TABLE IF NOT EXISTS import_runs (
+	id bigserial NOT NULL,
+	file_name varchar(255) NOT NULL,
+	file_sha256 varchar(64) NOT NULL,
+	entity varchar(20) NOT NULL,
+	profile varchar(100) NOT NULL,
+	mode varchar(20) NOT NULL,
+	loader varchar(20) NOT NULL,
+	"operator" varchar(100) NULL,
+	rows_parsed int4 DEFAULT 0 NOT NULL,
+	rows_inserted int4 DEFAULT 0 NOT NULL,
+	rows_rejected int4 DEFAULT 0 NOT NULL,
+	started_at timestamp(0) NOT NULL,
+	finished_at timestamp(0) NULL,
+	outcome varchar(20) NOT NULL,
+	message text NULL,
+	CONSTRAINT import_runs_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_import_runs_file_sha256 ON import_runs USING btree (file_sha256);
+ALTER TABLE import_runs ADD COLUMN IF NOT EXISTS rolled_back_at timestamp(0) NULL;
+CREATE TABLE IF NOT EXISTS import_run_rows (
+	id bigserial NOT NULL,
+	run_id int8 NOT NULL,
+	table_name varchar(63) NOT NULL,
+	row_id int8 NOT NULL,
+	"action" varchar(10) NOT NULL,
+	before_image jsonb NULL,
+	CONSTRAINT import_run_rows_pkey PRIMARY KEY (id),
+	CONSTRAINT import_run_rows_run_id_fkey FOREIGN KEY (run_id) REFERENCES import_runs(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_import_run_rows_run_id ON import_run_rows USING btree (run_id);
+CREATE INDEX IF NOT EXISTS idx_import_run_rows_row ON import_run_rows USING btree (table_name, row_id)`
+
+// ImportRun satu baris riwayat di tabel import_runs
+type ImportRun struct {
+	ID           int64
+	FileName     string
+	FileSHA256   string
+	Entity       string
+	Profile      string
+	Mode         string
+	Loader       string
+	Operator     string
+	RowsParsed   int
+	RowsInserted int
+	RowsRejected int
+	StartedAt    time.Time
+	FinishedAt   *time.Time
+	Outcome      string
+	Message      string
+
+	db *sql.DB
+}
+
+// StartImportRun membuat tabel import_runs jika belum ada lalu mencatat run baru dengan
+// outcome running. ID run diisi dari database.
+func StartImportRun(ctx context.Context, db *sql.DB, run *ImportRun) error {
+	if _, err := db.ExecContext(ctx, importRunsDDL); err != nil {
+		return fmt.Errorf("error creating import_runs table: %v", err)
+	}
+
+	run.db = db
+	run.StartedAt = time.Now()
+	run.Outcome = RunRunning
+	err := db.QueryRowContext(ctx, `INSERT INTO import_runs
+		(file_name, file_sha256, entity, profile, mode, loader, "operator", started_at, outcome)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		run.FileName, run.FileSHA256, run.Entity, run.Profile, run.Mode, run.Loader,
+		nullString(run.Operator), run.StartedAt, run.Outcome,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("error recording import run: %v", err)
+	}
+	return nil
+}
+
+// Finish mencatat jumlah baris, waktu selesai dan hasil akhir run. Memakai context
+// tersendiri agar tetap tercatat walaupun import dihentikan dengan sinyal.
+func (r *ImportRun) Finish(outcome, message string) error {
+	finished := time.Now()
+	r.FinishedAt = &finished
+	r.Outcome = outcome
+	r.Message = message
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `UPDATE import_runs SET
+		rows_parsed = $1, rows_inserted = $2, rows_rejected = $3, finished_at = $4, outcome = $5, message = $6
+		WHERE id = $7`,
+		r.RowsParsed, r.RowsInserted, r.RowsRejected, finished, outcome, nullString(message), r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating import run %d: %v", r.ID, err)
+	}
+	return nil
+}
+
+// nullString mengubah string kosong menjadi NULL
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// SeederWriter menulis file seeder secara bertahap, batch demi batch, untuk import
+// streaming yang jumlah barisnya belum diketahui di awal. Total baris ditulis di akhir file.
+type SeederWriter struct {
+	file  *os.File
+	t     table
+	opts  InsertOptions
+	path  string
+	total int
+}
+
+// NewMItemSeeder membuat file seeder m_item untuk ditulis bertahap dengan WriteMItems
+func NewMItemSeeder(outputPath string, opts InsertOptions) (*SeederWriter, error) {
+	return newSeederWriter(mItemTable, outputPath, opts)
+}
+
+// NewMSuppSeeder membuat file seeder m_supp untuk ditulis bertahap dengan WriteMSupps
+func NewMSuppSeeder(outputPath string, opts InsertOptions) (*SeederWriter, error) {
+	return newSeederWriter(mSuppTable, outputPath, opts)
+}
+
+func newSeederWriter(t table, outputPath string, opts InsertOptions) (*SeederWriter, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Mode == ModeUpsert {
+		if _, err := t.upsertKey(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("error creating seeder file: %v", err)
+	}
+
+	_, err = file.WriteString(fmt.Sprintf("-- Generated seeder file for %s table\n-- Generated at: %s\n\n",
+		t.Name, time.Now().Format("2006-01-02 15:04:05")))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &SeederWriter{file: file, t: t, opts: opts, path: outputPath}, nil
+}
+
+// WriteMItems menambahkan items ke file seeder. Supplier yang belum ada dicek (atau dibuat)
+// per batch, sebelum INSERT batch tersebut.
+func (w *SeederWriter) WriteMItems(items []MItem) error {
+	return w.write(mItemSeederRows(items), supplierSeederPreamble(items, w.opts.MissingSupplier))
+}
+
+// WriteMSupps menambahkan supplier ke file seeder
+func (w *SeederWriter) WriteMSupps(supps []MSupp) error {
+	return w.write(mSuppRows(supps), "")
+}
+
+func (w *SeederWriter) write(rows [][]interface{}, preamble string) error {
+	if preamble != "" {
+		if _, err := w.file.WriteString(preamble); err != nil {
+			return err
+		}
+	}
+
+	batchSize := w.t.batchSize()
+	for i := 0; i < len(rows); i += batchSize {
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		if err := writeBatchSQL(w.file, w.t, rows[i:end], w.total+i+1, w.opts); err != nil {
+			return fmt.Errorf("error writing batch %d-%d: %v", w.total+i+1, w.total+end, err)
+		}
+	}
+	w.total += len(rows)
+
+	return nil
+}
+
+// Close menulis total baris di akhir file lalu menutup file
+func (w *SeederWriter) Close() error {
+	if _, err := w.file.WriteString(fmt.Sprintf("-- Total items: %d\n", w.total)); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("error closing seeder file: %v", err)
+	}
+
+	log.Printf("Seeder file written: %s (%d items)", w.path, w.total)
+	return nil
+}
@@ -0,0 +1,306 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// LoaderStaging memuat data ke tabel staging, memvalidasi dengan SQL, lalu merge ke tabel tujuan
+const LoaderStaging = "staging"
+
+// stagingRowColumn kolom tambahan di tabel staging berisi index baris pada slice input
+const stagingRowColumn = "import_row"
+
+// stagingSampleLimit jumlah key contoh yang ditampilkan pada ringkasan diff
+const stagingSampleLimit = 10
+
+// Staging tabel staging sementara beserta transaction yang memilikinya. Tidak ada yang
+// ditulis ke tabel tujuan sampai Merge dipanggil; Rollback membuang semuanya.
+type Staging struct {
+	tx   *sql.Tx
+	t    table
+	opts InsertOptions
+	key  string // Kolom natural key untuk cek duplikat, diff dan upsert
+	Name string // Nama tabel staging
+	Rows int    // Jumlah baris yang dimuat ke staging
+}
+
+// StagingDiff ringkasan perubahan yang akan dilakukan Merge terhadap tabel tujuan
+type StagingDiff struct {
+	Rows         int      // Baris di staging
+	New          int      // Baris yang key-nya belum ada di tabel tujuan
+	Existing     int      // Baris yang key-nya sudah ada di tabel tujuan
+	ExistingKeys []string // Contoh key yang sudah ada (maksimal stagingSampleLimit)
+}
+
+// stagingCheck satu aturan validasi set-based: baris staging yang memenuhi Condition ditolak
+type stagingCheck struct {
+	Column    string
+	Condition string
+	Message   string
+}
+
+// StageMItems memuat items ke tabel staging sementara. Supplier item yang belum ada di m_supp
+// dibuat di transaction staging, sehingga hanya tersimpan jika staging di-merge.
+func StageMItems(ctx context.Context, db *sql.DB, items []MItem, opts InsertOptions) (*Staging, error) {
+	rows := mItemRows(items)
+	opts.prepare = itemSupplierPreparer(items, rows, opts.RunID)
+	return stageRows(ctx, db, mItemTable, rows, opts)
+}
+
+// stageRows membuat tabel staging di dalam transaction baru lalu memuat rows dengan COPY.
+// Kolom staging memakai tipe tanpa panjang/presisi agar nilai yang melanggar tetap masuk
+// dan bisa dilaporkan oleh Validate.
+func stageRows(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) (*Staging, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	key, err := t.upsertKey(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	s := &Staging{tx: tx, t: t, opts: opts, key: key, Name: "import_staging_" + t.Name, Rows: len(rows)}
+
+	// Data referensi (misalnya supplier baru) dibuat di transaction staging sehingga ikut
+	// di-rollback jika staging tidak di-merge
+	if opts.prepare != nil {
+		if err := opts.prepare(tx); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	definitions := make([]string, 0, len(t.Columns)+1)
+	definitions = append(definitions, stagingRowColumn+" int4 NOT NULL")
+	for _, column := range t.Columns {
+		definitions = append(definitions, column.Name+" "+column.Type)
+	}
+	_, err = tx.Exec(fmt.Sprintf("CREATE TEMP TABLE %s (\n\t%s\n) ON COMMIT DROP", s.Name, strings.Join(definitions, ",\n\t")))
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating staging table: %v", err)
+	}
+
+	staged := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		staged[i] = append([]interface{}{i}, row...)
+	}
+	columns := append([]string{stagingRowColumn}, copyColumnNames(t.Columns)...)
+	if err := copyInto(tx, s.Name, columns, staged); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// stageAndMerge memuat rows ke staging, memvalidasinya, lalu merge jika tidak ada baris yang
+// melanggar. Jika ada pelanggaran, tidak ada yang ditulis ke tabel tujuan.
+func stageAndMerge(ctx context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions) error {
+	s, err := stageRows(ctx, db, t, rows, opts)
+	if err != nil {
+		return err
+	}
+	defer s.Rollback()
+
+	rowErrors, err := s.Validate()
+	if err != nil {
+		return err
+	}
+	if len(rowErrors) > 0 {
+		return fmt.Errorf("%d staging check violations (first: row %d: %s), nothing was written to %s",
+			len(rowErrors), rowErrors[0].Index+1, rowErrors[0].Message, t.Name)
+	}
+
+	updated, inserted, err := s.Merge()
+	if err != nil {
+		return err
+	}
+	log.Printf("Merged %s into %s: %d updated, %d inserted", s.Name, t.Name, updated, inserted)
+	return nil
+}
+
+// checks menyusun aturan validasi staging: duplikat natural key, foreign key dan
+// batasan kolom. Kolom integer sudah dijamin oleh tipe kolom staging. Pada mode upsert
+// duplikat tidak ditolak karena merge memakai baris terakhir untuk setiap key.
+func (s *Staging) checks() []stagingCheck {
+	var checks []stagingCheck
+	if s.opts.Mode != ModeUpsert {
+		key := strings.Trim(s.key, `"`)
+		checks = append(checks, stagingCheck{
+			Column:    s.key,
+			Condition: fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s IS NOT NULL GROUP BY %s HAVING count(*) > 1)", s.key, s.key, s.Name, s.key, s.key),
+			Message:   fmt.Sprintf("duplicate %s in workbook", key),
+		})
+	}
+
+	for _, column := range s.t.Columns {
+		name := strings.Trim(column.Name, `"`)
+		if ref, ok := s.t.ForeignKeys[column.Name]; ok {
+			checks = append(checks, stagingCheck{
+				Column:    column.Name,
+				Condition: fmt.Sprintf("%s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %s r WHERE r.id = %s.%s)", column.Name, ref, s.Name, column.Name),
+				Message:   fmt.Sprintf("%s not found in %s", name, ref),
+			})
+		}
+
+		constraint, ok := s.t.Constraints[column.Name]
+		if !ok {
+			continue
+		}
+		if constraint.NotNull {
+			checks = append(checks, stagingCheck{column.Name, column.Name + " IS NULL", name + " must not be NULL"})
+		}
+		if constraint.MaxLength > 0 {
+			checks = append(checks, stagingCheck{
+				Column:    column.Name,
+				Condition: fmt.Sprintf("char_length(%s) > %d", column.Name, constraint.MaxLength),
+				Message:   fmt.Sprintf("%s exceeds %d characters", name, constraint.MaxLength),
+			})
+		}
+		if constraint.Precision > 0 {
+			limit := "1" + strings.Repeat("0", constraint.Precision-constraint.Scale)
+			checks = append(checks, stagingCheck{
+				Column:    column.Name,
+				Condition: fmt.Sprintf("abs(round(%s, %d)) >= %s", column.Name, constraint.Scale, limit),
+				Message:   fmt.Sprintf("%s exceeds numeric(%d,%d), absolute value must be less than %s", name, constraint.Precision, constraint.Scale, limit),
+			})
+		}
+	}
+	return checks
+}
+
+// Validate menjalankan aturan validasi set-based terhadap tabel staging dan
+// mengembalikan baris yang melanggar, berurutan per aturan lalu per baris
+func (s *Staging) Validate() ([]RowError, error) {
+	var rowErrors []RowError
+	for _, check := range s.checks() {
+		query := fmt.Sprintf("SELECT %s, %s::text FROM %s WHERE %s ORDER BY %s",
+			stagingRowColumn, check.Column, s.Name, check.Condition, stagingRowColumn)
+		rows, err := s.tx.Query(query)
+		if err != nil {
+			return nil, fmt.Errorf("error running staging check '%s': %v", check.Message, err)
+		}
+
+		found := 0
+		for rows.Next() {
+			var index int
+			var value sql.NullString
+			if err := rows.Scan(&index, &value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error reading staging check '%s': %v", check.Message, err)
+			}
+			rowErrors = append(rowErrors, RowError{
+				Index:   index,
+				Column:  strings.Trim(check.Column, `"`),
+				Value:   value.String,
+				Message: check.Message,
+			})
+			found++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading staging check '%s': %v", check.Message, err)
+		}
+		if found > 0 {
+			log.Printf("Staging check '%s': %d rows", check.Message, found)
+		}
+	}
+	return rowErrors, nil
+}
+
+// Reject menghapus baris yang melanggar dari tabel staging sehingga tidak ikut di-merge
+func (s *Staging) Reject(rowErrors []RowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(rowErrors))
+	for i, rowErr := range rowErrors {
+		ids[i] = int64(rowErr.Index)
+	}
+
+	result, err := s.tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ANY($1)", s.Name, stagingRowColumn), pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error removing rejected rows from staging: %v", err)
+	}
+	removed, _ := result.RowsAffected()
+	s.Rows -= int(removed)
+	return nil
+}
+
+// Diff menghitung berapa baris staging yang baru dan berapa yang key-nya sudah ada di tabel tujuan
+func (s *Staging) Diff() (StagingDiff, error) {
+	diff := StagingDiff{Rows: s.Rows}
+	exists := fmt.Sprintf("EXISTS (SELECT 1 FROM %s t WHERE t.%s = s.%s)", s.t.Name, s.key, s.key)
+
+	err := s.tx.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s s WHERE %s", s.Name, exists)).Scan(&diff.Existing)
+	if err != nil {
+		return diff, fmt.Errorf("error comparing staging with %s: %v", s.t.Name, err)
+	}
+	diff.New = diff.Rows - diff.Existing
+
+	rows, err := s.tx.Query(fmt.Sprintf("SELECT s.%s::text FROM %s s WHERE %s ORDER BY s.%s LIMIT %d",
+		s.key, s.Name, exists, stagingRowColumn, stagingSampleLimit))
+	if err != nil {
+		return diff, fmt.Errorf("error comparing staging with %s: %v", s.t.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return diff, fmt.Errorf("error reading staging diff: %v", err)
+		}
+		diff.ExistingKeys = append(diff.ExistingKeys, key)
+	}
+	return diff, rows.Err()
+}
+
+// Merge menulis seluruh isi staging ke tabel tujuan dalam satu statement lalu commit.
+// Mode upsert memakai query upsert yang sama dengan loader insert.
+func (s *Staging) Merge() (updated, inserted int, err error) {
+	names := columnNames(s.t.Columns)
+	source := fmt.Sprintf("SELECT %s FROM %s ORDER BY %s", names, s.Name, stagingRowColumn)
+
+	if s.opts.Mode == ModeUpsert {
+		err = s.tx.QueryRow(buildUpsertFromSource(s.t.Name, s.t.Columns, s.key, source, s.opts.RunID)).Scan(&updated, &inserted)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error merging staging into %s: %w", s.t.Name, err)
+		}
+	} else if s.opts.RunID > 0 {
+		err = s.tx.QueryRow(buildTrackedInsert(s.t.Name, s.t.Columns, source, s.opts.RunID)).Scan(&inserted)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error merging staging into %s: %w", s.t.Name, err)
+		}
+	} else {
+		result, err := s.tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) %s", s.t.Name, names, source))
+		if err != nil {
+			return 0, 0, fmt.Errorf("error merging staging into %s: %w", s.t.Name, err)
+		}
+		affected, _ := result.RowsAffected()
+		inserted = int(affected)
+	}
+
+	if err := s.tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return updated, inserted, nil
+}
+
+// Rollback membuang tabel staging tanpa menyentuh tabel tujuan. Aman dipanggil setelah Merge.
+func (s *Staging) Rollback() {
+	if err := s.tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		log.Printf("Warning: error rolling back staging: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+// TestStagingChecksDuplicateKey memastikan duplikat natural key hanya ditolak pada mode
+// insert; pada mode upsert merge memakai baris terakhir untuk setiap key
+func TestStagingChecksDuplicateKey(t *testing.T) {
+	tests := []struct {
+		mode string
+		want bool
+	}{
+		{ModeInsert, true},
+		{ModeUpsert, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			s := &Staging{t: mItemTable, opts: InsertOptions{Mode: tt.mode}, key: "barcode", Name: "import_staging_m_item"}
+
+			found := false
+			for _, check := range s.checks() {
+				if check.Message == "duplicate barcode in workbook" {
+					found = true
+				}
+			}
+			if found != tt.want {
+				t.Errorf("duplicate key check present = %v, want %v", found, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type MSupp struct {
+	ID          int64      `db:"id"`
+	Code        *string    `db:"code"`
+	Origin      *string    `db:"origin"`
+	GroupSuppID *int64     `db:"group_supp_id"`
+	Type        *string    `db:"type"`
+	Name        string     `db:"name"`
+	NIB         *string    `db:"nib"`
+	TopID       *int64     `db:"top_id"`
+	FlagPPN     bool       `db:"flag_ppn"`
+	NPWP        *string    `db:"npwp"`
+	Addr        *string    `db:"addr"`
+	ProvID      *int64     `db:"prov_id"`
+	CityID      *int64     `db:"city_id"`
+	DistrictID  *int64     `db:"district_id"`
+	PostCode    *string    `db:"post_code"`
+	CoaHutangID *int64     `db:"coa_hutang_id"`
+	Phone1      *string    `db:"phone1"`
+	Phone2      *string    `db:"phone2"`
+	CP1         *string    `db:"cp1"`
+	CP1Phone    *string    `db:"cp1_phone"`
+	CP2         *string    `db:"cp2"`
+	CP2Phone    *string    `db:"cp2_phone"`
+	Desc        *string    `db:"desc"`
+	IsActive    bool       `db:"is_active"`
+	CreatorID   *int32     `db:"creator_id"`
+	EditorID    *int32     `db:"editor_id"`
+	CreatedAt   *time.Time `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+
+	SourceSheet string `db:"-"` // Nama sheet asal baris, untuk pesan error pada import multi-sheet
+	SourceRow   int    `db:"-"` // Nomor baris di Excel (1-based), untuk pesan error
+}
+
+// mSuppColumns daftar kolom m_supp yang ditulis saat insert (tanpa id yang auto-increment).
+// Nilai setiap kolom diambil dari field MSupp dengan tag db yang sama.
+var mSuppColumns = [...]sqlColumn{
+	{"code", "varchar"}, {"origin", "varchar"}, {"group_supp_id", "int8"}, {`"type"`, "varchar"},
+	{`"name"`, "varchar"}, {"nib", "varchar"}, {"top_id", "int8"}, {"flag_ppn", "bool"},
+	{"npwp", "varchar"}, {"addr", "text"}, {"prov_id", "int8"}, {"city_id", "int8"},
+	{"district_id", "int8"}, {"post_code", "varchar"}, {"coa_hutang_id", "int8"},
+	{"phone1", "varchar"}, {"phone2", "varchar"}, {"cp1", "varchar"}, {"cp1_phone", "varchar"},
+	{"cp2", "varchar"}, {"cp2_phone", "varchar"}, {`"desc"`, "text"}, {"is_active", "bool"},
+	{"creator_id", "int4"}, {"editor_id", "int4"}, {"created_at", "timestamp"}, {"updated_at", "timestamp"},
+}
+
+// MSuppColumnCount jumlah kolom dalam tabel m_supp (tanpa id yang auto-increment)
+const MSuppColumnCount = len(mSuppColumns)
+
+// mSuppTable definisi tabel m_supp untuk batch insert dan seeder
+var mSuppTable = table{
+	Name:    "m_supp",
+	Columns: mSuppColumns[:],
+	Constraints: map[string]columnConstraint{
+		"code":      {MaxLength: 255},
+		"origin":    {MaxLength: 100},
+		`"type"`:    {MaxLength: 100},
+		`"name"`:    {MaxLength: 100, NotNull: true},
+		"nib":       {MaxLength: 100},
+		"npwp":      {MaxLength: 255},
+		"post_code": {MaxLength: 10},
+		"phone1":    {MaxLength: 100},
+		"phone2":    {MaxLength: 100},
+		"cp1":       {MaxLength: 255},
+		"cp1_phone": {MaxLength: 100},
+		"cp2":       {MaxLength: 255},
+		"cp2_phone": {MaxLength: 100},
+	},
+	UpsertKeys: map[string]string{
+		"code": "code",
+		"name": `"name"`,
+		"npwp": "npwp",
+	},
+	DefaultUpsertKey: "code",
+}
+
+// mSuppFields index field MSupp untuk setiap kolom di mSuppColumns
+var mSuppFields = columnFields(MSupp{}, mSuppColumns[:])
+
+// columnValues mengembalikan nilai supplier sesuai urutan mSuppColumns
+func (supp MSupp) columnValues() []interface{} {
+	return fieldValues(reflect.ValueOf(supp), mSuppFields)
+}
+
+// scanTargets mengembalikan pointer ke field supplier sesuai urutan id lalu mSuppColumns,
+// untuk rows.Scan hasil SELECT dari m_supp
+func (supp *MSupp) scanTargets() []interface{} {
+	return append([]interface{}{&supp.ID}, fieldPointers(reflect.ValueOf(supp).Elem(), mSuppFields)...)
+}
+
+// mSuppRows mengubah suppliers menjadi baris nilai sesuai urutan mSuppColumns
+func mSuppRows(supps []MSupp) [][]interface{} {
+	rows := make([][]interface{}, len(supps))
+	for i, supp := range supps {
+		rows[i] = supp.columnValues()
+	}
+	return rows
+}
+
+// InsertMSupps melakukan batch insert (atau upsert) data supplier ke tabel m_supp
+func InsertMSupps(ctx context.Context, db *sql.DB, supps []MSupp, opts InsertOptions) error {
+	return insertRows(ctx, db, mSuppTable, mSuppRows(supps), opts)
+}
+
+// CheckMSuppConstraints memeriksa panjang teks dan kolom NOT NULL sesuai definisi m_supp
+func CheckMSuppConstraints(supps []MSupp) []RowError {
+	return checkConstraints(mSuppTable, mSuppRows(supps))
+}
+
+// DryRunMSupps menjalankan seluruh batch insert supplier di dalam satu transaction
+// lalu me-rollback-nya, dan mengembalikan baris yang ditolak database
+func DryRunMSupps(ctx context.Context, db *sql.DB, supps []MSupp, opts InsertOptions) ([]RowError, error) {
+	return dryRunRows(ctx, db, mSuppTable, mSuppRows(supps), opts)
+}
+
+// StageMSupps memuat supplier ke tabel staging sementara
+func StageMSupps(ctx context.Context, db *sql.DB, supps []MSupp, opts InsertOptions) (*Staging, error) {
+	return stageRows(ctx, db, mSuppTable, mSuppRows(supps), opts)
+}
+
+// GenerateSupplierSeederSQL membuat file SQL seeder dari data supplier
+func GenerateSupplierSeederSQL(supps []MSupp, outputPath string, opts InsertOptions) error {
+	if len(supps) == 0 {
+		return fmt.Errorf("no suppliers to generate seeder")
+	}
+	return generateSeeder(mSuppTable, mSuppRows(supps), outputPath, "", opts)
+}
+
+const (
+	MissingSupplierReject = "reject" // Item dengan supplier yang tidak dikenal ditolak
+	MissingSupplierCreate = "create" // Supplier yang tidak dikenal dibuat otomatis di m_supp
+)
+
+// SupplierLookup mencari id supplier berdasarkan kode atau nama (case-insensitive)
+type SupplierLookup struct {
+	byCode map[string]int64
+	byName map[string][]int64
+}
+
+// LoadSupplierLookup membaca seluruh kode dan nama supplier dari m_supp
+func LoadSupplierLookup(ctx context.Context, db *sql.DB) (*SupplierLookup, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, code, "name" FROM m_supp`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying m_supp: %v", err)
+	}
+	defer rows.Close()
+
+	lookup := &SupplierLookup{
+		byCode: make(map[string]int64),
+		byName: make(map[string][]int64),
+	}
+	for rows.Next() {
+		var (
+			id   int64
+			code sql.NullString
+			name string
+		)
+		if err := rows.Scan(&id, &code, &name); err != nil {
+			return nil, fmt.Errorf("error scanning m_supp: %v", err)
+		}
+		lookup.add(id, code.String, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading m_supp: %v", err)
+	}
+
+	return lookup, nil
+}
+
+func (l *SupplierLookup) add(id int64, code, name string) {
+	if key := supplierKey(code); key != "" {
+		if _, exists := l.byCode[key]; !exists {
+			l.byCode[key] = id
+		}
+	}
+	if key := supplierKey(name); key != "" {
+		l.byName[key] = append(l.byName[key], id)
+	}
+}
+
+// Find mencari id supplier. Kode dicocokkan lebih dulu, baru nama.
+// Nama yang dimiliki lebih dari satu supplier dianggap ambigu.
+func (l *SupplierLookup) Find(value string) (int64, bool, error) {
+	key := supplierKey(value)
+	if id, ok := l.byCode[key]; ok {
+		return id, true, nil
+	}
+
+	ids := l.byName[key]
+	switch len(ids) {
+	case 0:
+		return 0, false, nil
+	case 1:
+		return ids[0], true, nil
+	default:
+		return 0, false, fmt.Errorf("supplier '%s' is ambiguous, %d suppliers share this name; use the supplier code instead", value, len(ids))
+	}
+}
+
+// supplierKey menormalkan kode/nama supplier untuk pencarian case-insensitive
+func supplierKey(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// SupplierRejection item yang ditolak karena supplier-nya tidak bisa di-resolve
+type SupplierRejection struct {
+	Item   MItem
+	Reason string
+}
+
+// ResolveItemSuppliers mengisi MSuppID dari SupplierName. Item dengan supplier yang tidak
+// ditemukan ditolak (MissingSupplierReject) atau dibiarkan tanpa MSuppID (MissingSupplierCreate);
+// supplier-nya baru dibuat saat item ditulis, di transaction yang sama dengan item-nya.
+// Mengembalikan item yang lolos dan daftar item yang ditolak.
+func ResolveItemSuppliers(ctx context.Context, db *sql.DB, items []MItem, missing string) ([]MItem, []SupplierRejection, error) {
+	lookup, err := LoadSupplierLookup(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved, rejected := lookup.resolve(items, missing)
+	if names := missingSupplierNames(resolved, nil); len(names) > 0 {
+		log.Printf("%d suppliers not found in m_supp will be created with the items: %s", len(names), strings.Join(names, ", "))
+	}
+	return resolved, rejected, nil
+}
+
+// resolve mencocokkan SupplierName setiap item ke lookup tanpa menulis apa pun ke database
+func (l *SupplierLookup) resolve(items []MItem, missing string) ([]MItem, []SupplierRejection) {
+	resolved := make([]MItem, 0, len(items))
+	var rejected []SupplierRejection
+	for _, item := range items {
+		if item.SupplierName == nil || item.MSuppID != nil {
+			resolved = append(resolved, item)
+			continue
+		}
+
+		id, found, err := l.Find(*item.SupplierName)
+		if err != nil {
+			rejected = append(rejected, SupplierRejection{Item: item, Reason: err.Error()})
+			continue
+		}
+		if !found {
+			if missing == MissingSupplierCreate {
+				resolved = append(resolved, item)
+				continue
+			}
+			rejected = append(rejected, SupplierRejection{
+				Item:   item,
+				Reason: fmt.Sprintf("supplier '%s' not found in m_supp (by code or name)", *item.SupplierName),
+			})
+			continue
+		}
+
+		item.MSuppID = &id
+		resolved = append(resolved, item)
+	}
+
+	return resolved, rejected
+}
+
+// itemSupplierPreparer membuat InsertOptions.prepare yang membuat supplier untuk item yang
+// supplier-nya belum ada (SupplierName terisi tanpa MSuppID) lalu mengisi m_supp_id pada rows.
+// Mengembalikan nil jika tidak ada supplier yang perlu dibuat.
+func itemSupplierPreparer(items []MItem, rows [][]interface{}, runID int64) func(tx *sql.Tx) error {
+	names := missingSupplierNames(items, nil)
+	if len(names) == 0 {
+		return nil
+	}
+
+	return func(tx *sql.Tx) error {
+		ids, err := createMissingSuppliers(tx, names, runID)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			if item.SupplierName == nil || item.MSuppID != nil {
+				continue
+			}
+			if id, ok := ids[supplierKey(*item.SupplierName)]; ok {
+				rows[i][mItemSuppIDColumn] = &id
+			}
+		}
+		return nil
+	}
+}
+
+// createMissingSuppliers membuat supplier baru untuk setiap nama di dalam tx dan mengembalikan
+// id-nya per supplierKey. Jika runID diisi, id supplier baru dicatat ke import_run_rows.
+func createMissingSuppliers(tx *sql.Tx, names []string, runID int64) (map[string]int64, error) {
+	now := time.Now()
+	ids := make(map[string]int64, len(names))
+	for _, name := range names {
+		var id int64
+		err := tx.QueryRow(`INSERT INTO m_supp ("name", flag_ppn, is_active, created_at, updated_at)
+			VALUES ($1, false, true, $2, $2) RETURNING id`, name, now).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("error creating supplier '%s': %v", name, err)
+		}
+		if runID > 0 {
+			_, err = tx.Exec(`INSERT INTO import_run_rows (run_id, table_name, row_id, "action") VALUES ($1, $2, $3, $4)`,
+				runID, mSuppTable.Name, id, RunRowInsert)
+			if err != nil {
+				return nil, fmt.Errorf("error recording supplier '%s' for import run %d: %v", name, runID, err)
+			}
+		}
+		ids[supplierKey(name)] = id
+		log.Printf("Created supplier '%s' (id %d)", name, id)
+	}
+	return ids, nil
+}
+
+// missingSupplierNames mengembalikan nama supplier unik yang belum dikenal lookup.
+// Jika lookup nil, semua nama supplier dikembalikan.
+func missingSupplierNames(items []MItem, lookup *SupplierLookup) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, item := range items {
+		if item.SupplierName == nil || item.MSuppID != nil {
+			continue
+		}
+		key := supplierKey(*item.SupplierName)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if lookup != nil {
+			if _, found, _ := lookup.Find(key); found {
+				continue
+			}
+		}
+		names = append(names, strings.TrimSpace(*item.SupplierName))
+	}
+	return names
+}
+
+// supplierLookupExpr subquery yang mencari id supplier saat seeder dijalankan, dengan aturan
+// yang sama seperti SupplierLookup.Find: kode dicocokkan lebih dulu, baru nama. Nama yang
+// dimiliki lebih dari satu supplier membuat subquery gagal, dan sudah dihentikan lebih dulu
+// oleh pemeriksaan di supplierSeederPreamble.
+func supplierLookupExpr(value string) sqlExpr {
+	v := formatSQLValue(strings.TrimSpace(value))
+	return sqlExpr(fmt.Sprintf(`COALESCE((SELECT id FROM m_supp WHERE lower(code) = lower(%s) ORDER BY id LIMIT 1), (SELECT id FROM m_supp WHERE lower("name") = lower(%s)))`, v, v))
+}
+
+// supplierSeederPreamble membuat statement yang dijalankan sebelum insert item pada file seeder:
+// menghentikan seeder jika nama supplier dimiliki lebih dari satu supplier (ambigu), lalu
+// membuat supplier yang belum ada (MissingSupplierCreate) atau menghentikan seeder jika ada
+// supplier yang tidak dikenal (MissingSupplierReject).
+func supplierSeederPreamble(items []MItem, missing string) string {
+	names := missingSupplierNames(items, nil)
+	if len(names) == 0 {
+		return ""
+	}
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = "(" + formatSQLValue(name) + ")"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("-- Abort when an item references a supplier name shared by several suppliers\n")
+	fmt.Fprintf(&sb, `DO $$
+DECLARE
+	ambiguous text;
+BEGIN
+	SELECT string_agg(s.name, ', ') INTO ambiguous
+	FROM (VALUES %s) AS s(name)
+	WHERE NOT EXISTS (SELECT 1 FROM m_supp WHERE lower(code) = lower(s.name))
+	AND (SELECT count(*) FROM m_supp WHERE lower("name") = lower(s.name)) > 1;
+	IF ambiguous IS NOT NULL THEN
+		RAISE EXCEPTION 'suppliers are ambiguous in m_supp, use the supplier code instead: %%', ambiguous;
+	END IF;
+END $$;
+
+`, strings.Join(values, ", "))
+
+	if missing == MissingSupplierCreate {
+		sb.WriteString("-- Create suppliers referenced by items that do not exist yet\n")
+		for _, name := range names {
+			v := formatSQLValue(name)
+			fmt.Fprintf(&sb, `INSERT INTO m_supp ("name", flag_ppn, is_active, created_at, updated_at) SELECT %s, false, true, now(), now() WHERE NOT EXISTS (SELECT 1 FROM m_supp WHERE lower(code) = lower(%s) OR lower("name") = lower(%s));`+"\n", v, v, v)
+		}
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	sb.WriteString("-- Abort when an item references a supplier that does not exist\n")
+	fmt.Fprintf(&sb, `DO $$
+DECLARE
+	unknown text;
+BEGIN
+	SELECT string_agg(s.name, ', ') INTO unknown
+	FROM (VALUES %s) AS s(name)
+	WHERE NOT EXISTS (SELECT 1 FROM m_supp WHERE lower(code) = lower(s.name) OR lower("name") = lower(s.name));
+	IF unknown IS NOT NULL THEN
+		RAISE EXCEPTION 'suppliers not found in m_supp: %%', unknown;
+	END IF;
+END $$;
+
+`, strings.Join(values, ", "))
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSupplierLookupExpr(t *testing.T) {
+	got := string(supplierLookupExpr(" Sumber Jaya "))
+	want := `COALESCE((SELECT id FROM m_supp WHERE lower(code) = lower('Sumber Jaya') ORDER BY id LIMIT 1), (SELECT id FROM m_supp WHERE lower("name") = lower('Sumber Jaya')))`
+	if got != want {
+		t.Errorf("supplierLookupExpr() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSupplierSeederPreamble(t *testing.T) {
+	name := "Sumber Jaya"
+	items := []MItem{{ItemName: "GULA", SupplierName: &name}}
+
+	tests := []struct {
+		name    string
+		missing string
+		want    []string
+		absent  []string
+	}{
+		{
+			name:    "reject",
+			missing: MissingSupplierReject,
+			want:    []string{"suppliers are ambiguous in m_supp", "suppliers not found in m_supp"},
+			absent:  []string{"INSERT INTO m_supp"},
+		},
+		{
+			name:    "create",
+			missing: MissingSupplierCreate,
+			want:    []string{"suppliers are ambiguous in m_supp", "INSERT INTO m_supp"},
+			absent:  []string{"suppliers not found in m_supp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preamble := supplierSeederPreamble(items, tt.missing)
+			for _, part := range tt.want {
+				if !strings.Contains(preamble, part) {
+					t.Errorf("preamble does not contain %q:\n%s", part, preamble)
+				}
+			}
+			for _, part := range tt.absent {
+				if strings.Contains(preamble, part) {
+					t.Errorf("preamble contains %q:\n%s", part, preamble)
+				}
+			}
+			// Pemeriksaan ambigu harus dijalankan sebelum supplier baru dibuat
+			if strings.Index(preamble, "ambiguous") > strings.Index(preamble, "INSERT INTO m_supp") && tt.missing == MissingSupplierCreate {
+				t.Errorf("ambiguity check runs after creating suppliers:\n%s", preamble)
+			}
+		})
+	}
+
+	if preamble := supplierSeederPreamble([]MItem{{ItemName: "GULA"}}, MissingSupplierReject); preamble != "" {
+		t.Errorf("preamble for items without supplier = %q, want empty", preamble)
+	}
+}
+
+// TestSupplierLookupResolve memastikan supplier yang tidak dikenal tidak dibuat saat resolve:
+// dengan create item-nya dibiarkan tanpa MSuppID agar supplier dibuat bersama item-nya
+func TestSupplierLookupResolve(t *testing.T) {
+	lookup := &SupplierLookup{byCode: make(map[string]int64), byName: make(map[string][]int64)}
+	lookup.add(1, "SJ01", "Sumber Jaya")
+	lookup.add(2, "", "Toko Baru")
+	lookup.add(3, "", "Toko Baru")
+
+	names := []string{"sj01", "SUMBER JAYA", "Toko Baru", "Maju Makmur"}
+	items := make([]MItem, len(names))
+	for i := range names {
+		items[i] = MItem{ItemName: "ITEM", SupplierName: &names[i], SourceRow: i + 2}
+	}
+
+	tests := []struct {
+		missing    string
+		resolved   []int64 // MSuppID item yang lolos, 0 berarti belum ada supplier-nya
+		rejected   []int   // SourceRow item yang ditolak
+		preparable bool
+	}{
+		{MissingSupplierReject, []int64{1, 1}, []int{4, 5}, false},
+		{MissingSupplierCreate, []int64{1, 1, 0}, []int{4}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.missing, func(t *testing.T) {
+			resolved, rejected := lookup.resolve(items, tt.missing)
+
+			ids := make([]int64, len(resolved))
+			for i, item := range resolved {
+				if item.MSuppID != nil {
+					ids[i] = *item.MSuppID
+				}
+			}
+			rows := make([]int, len(rejected))
+			for i, rejection := range rejected {
+				rows[i] = rejection.Item.SourceRow
+			}
+			if !reflect.DeepEqual(ids, tt.resolved) || !reflect.DeepEqual(rows, tt.rejected) {
+				t.Errorf("resolved %v rejected rows %v, want %v and %v", ids, rows, tt.resolved, tt.rejected)
+			}
+
+			prepare := itemSupplierPreparer(resolved, mItemRows(resolved), 0)
+			if (prepare != nil) != tt.preparable {
+				t.Errorf("itemSupplierPreparer() != nil is %v, want %v", prepare != nil, tt.preparable)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SyncPlan baris m_item yang akan dinonaktifkan oleh import -sync: baris aktif dalam scope
+// m_bu_id workbook yang natural key-nya tidak ada di workbook
+type SyncPlan struct {
+	Key     string   // Kolom natural key untuk mencocokkan baris (barcode atau code)
+	BuIDs   []int64  // Scope m_bu_id dari workbook
+	NullBu  bool     // Scope juga mencakup baris dengan m_bu_id NULL
+	Keys    []string // Natural key seluruh baris workbook
+	Active  int      // Baris aktif dalam scope yang memiliki key
+	Missing int      // Baris aktif dalam scope yang tidak ada di workbook
+}
+
+// PlanMItemSync menghitung berapa baris m_item yang akan dinonaktifkan jika items adalah
+// katalog lengkap. Scope diambil dari nilai m_bu_id items; item tanpa m_bu_id membuat baris
+// dengan m_bu_id NULL ikut dalam scope. Baris m_item tanpa key tidak pernah dinonaktifkan
+// karena tidak bisa dicocokkan dengan workbook.
+func PlanMItemSync(ctx context.Context, db *sql.DB, items []MItem, opts InsertOptions) (*SyncPlan, error) {
+	key, err := mItemTable.upsertKey(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{Key: key}
+	seenBu := make(map[int64]bool)
+	for _, item := range items {
+		if item.MBuID == nil {
+			plan.NullBu = true
+		} else if !seenBu[*item.MBuID] {
+			seenBu[*item.MBuID] = true
+			plan.BuIDs = append(plan.BuIDs, *item.MBuID)
+		}
+
+		value := item.Barcode
+		if key == "code" {
+			value = item.Code
+		}
+		if value != nil && *value != "" {
+			plan.Keys = append(plan.Keys, *value)
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT count(*), count(*) FILTER (WHERE %s)
+		FROM %s t WHERE %s`, plan.missingCondition(), mItemTable.Name, plan.scopeCondition())
+	err = db.QueryRowContext(ctx, query, pq.Array(plan.BuIDs), plan.NullBu, pq.Array(plan.Keys)).Scan(&plan.Active, &plan.Missing)
+	if err != nil {
+		return nil, fmt.Errorf("error counting %s rows to deactivate: %v", mItemTable.Name, err)
+	}
+	return plan, nil
+}
+
+// Percent persentase baris aktif dalam scope yang akan dinonaktifkan
+func (p *SyncPlan) Percent() float64 {
+	if p.Active == 0 {
+		return 0
+	}
+	return float64(p.Missing) * 100 / float64(p.Active)
+}
+
+// Scope deskripsi scope m_bu_id untuk log
+func (p *SyncPlan) Scope() string {
+	parts := make([]string, 0, len(p.BuIDs)+1)
+	for _, id := range p.BuIDs {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	if p.NullBu {
+		parts = append(parts, "NULL")
+	}
+	return "m_bu_id " + strings.Join(parts, ", ")
+}
+
+// scopeCondition baris aktif dalam scope m_bu_id ($1, $2) yang memiliki key
+func (p *SyncPlan) scopeCondition() string {
+	return fmt.Sprintf("t.is_active AND (t.m_bu_id = ANY($1) OR ($2 AND t.m_bu_id IS NULL)) AND t.%s IS NOT NULL AND t.%s <> ''", p.Key, p.Key)
+}
+
+// missingCondition baris yang key-nya tidak ada di workbook ($3)
+func (p *SyncPlan) missingCondition() string {
+	return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM unnest($3::text[]) k(v) WHERE k.v = t.%s)", p.Key)
+}
+
+// DeactivateMItems menonaktifkan baris dalam plan (is_active = false, updated_at diisi).
+// Kondisi plan dievaluasi ulang di dalam statement, sehingga jumlahnya bisa berbeda dari
+// plan.Missing jika m_item berubah sejak PlanMItemSync. Jika runID diisi, isi lama baris
+// dicatat ke import_run_rows agar RollbackImportRun bisa mengaktifkannya kembali.
+func DeactivateMItems(ctx context.Context, db *sql.DB, plan *SyncPlan, runID int64) (int, error) {
+	query := fmt.Sprintf(`WITH old AS (
+	SELECT t.* FROM %s t WHERE %s AND %s FOR UPDATE
+),
+upd AS (
+	UPDATE %s t SET is_active = false, updated_at = $4 FROM old o WHERE t.id = o.id RETURNING t.id
+)`, mItemTable.Name, plan.scopeCondition(), plan.missingCondition(), mItemTable.Name)
+	if runID > 0 {
+		query += fmt.Sprintf(`,
+tracked AS (
+	INSERT INTO import_run_rows (run_id, table_name, row_id, "action", before_image)
+	SELECT %d, '%s', o.id, '%s', to_jsonb(o) FROM old o
+)`, runID, mItemTable.Name, RunRowUpdate)
+	}
+	query += "\nSELECT count(*) FROM upd"
+
+	var deactivated int
+	err := db.QueryRowContext(ctx, query, pq.Array(plan.BuIDs), plan.NullBu, pq.Array(plan.Keys), time.Now()).Scan(&deactivated)
+	if err != nil {
+		return 0, fmt.Errorf("error deactivating %s rows: %v", mItemTable.Name, err)
+	}
+	return deactivated, nil
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const (
+	ModeInsert = "insert" // Selalu INSERT baris baru
+	ModeUpsert = "upsert" // UPDATE baris yang sudah ada berdasarkan natural key, INSERT sisanya
+)
+
+// upsertPreservedColumns kolom yang tidak diubah ketika baris sudah ada di database
+var upsertPreservedColumns = map[string]bool{
+	"created_at": true,
+	"creator_id": true,
+}
+
+// InsertOptions mengatur cara data ditulis ke database maupun ke file seeder
+type InsertOptions struct {
+	Mode      string // ModeInsert atau ModeUpsert
+	UpsertKey string // Natural key untuk mode upsert, misalnya barcode atau code; kosong berarti default tabel
+
+	// MissingSupplier menentukan perlakuan item yang supplier-nya tidak ada di m_supp:
+	// MissingSupplierReject (default) atau MissingSupplierCreate
+	MissingSupplier string
+
+	// Atomic menjalankan semua batch di dalam satu transaction (savepoint per batch),
+	// sehingga import berhasil seluruhnya atau tidak sama sekali
+	Atomic bool
+
+	// Loader cara data dikirim ke database: LoaderInsert (default), LoaderCopy atau
+	// LoaderStaging. LoaderCopy dan LoaderStaging selalu berjalan di dalam satu transaction.
+	Loader string
+
+	// Workers jumlah batch yang di-insert paralel, masing-masing dengan transaction sendiri.
+	// 0 atau 1 berarti berurutan; dibatasi oleh MaxOpenConns connection pool.
+	Workers int
+
+	// OnCommit dipanggil setiap kali batch rows[start:end] commit, misalnya untuk menyimpan
+	// checkpoint. Hanya dipakai loader insert tanpa Atomic, karena loader lain menulis
+	// semua baris dalam satu transaction.
+	OnCommit func(start, end int)
+
+	// RunID id run di import_runs. Jika diisi, id baris yang di-insert dan isi lama baris
+	// yang di-update dicatat ke import_run_rows di transaction yang sama dengan datanya,
+	// sehingga run bisa dibatalkan dengan RollbackImportRun.
+	RunID int64
+
+	// prepare dijalankan sebelum batch pertama di transaction yang sama dengan datanya,
+	// misalnya untuk membuat supplier yang dirujuk item. Loader yang memakai transaction per
+	// batch menjalankannya di transaction tersendiri sebelum batch pertama.
+	prepare func(tx *sql.Tx) error
+}
+
+// Validate memastikan mode yang dipilih dikenali. Natural key divalidasi per tabel saat insert.
+func (o InsertOptions) Validate() error {
+	switch o.Mode {
+	case "", ModeInsert, ModeUpsert:
+	default:
+		return fmt.Errorf("invalid mode '%s', use '%s' or '%s'", o.Mode, ModeInsert, ModeUpsert)
+	}
+
+	switch o.MissingSupplier {
+	case "", MissingSupplierReject, MissingSupplierCreate:
+	default:
+		return fmt.Errorf("invalid missing supplier policy '%s', use '%s' or '%s'", o.MissingSupplier, MissingSupplierReject, MissingSupplierCreate)
+	}
+
+	switch o.Loader {
+	case "", LoaderInsert, LoaderCopy, LoaderStaging:
+	default:
+		return fmt.Errorf("invalid loader '%s', use '%s', '%s' or '%s'", o.Loader, LoaderInsert, LoaderCopy, LoaderStaging)
+	}
+
+	if o.Workers < 0 {
+		return fmt.Errorf("invalid workers %d, must be at least 1", o.Workers)
+	}
+	if o.Workers > 1 && (o.Atomic || (o.Loader != "" && o.Loader != LoaderInsert)) {
+		return fmt.Errorf("parallel workers only work with the insert loader and without atomic mode")
+	}
+	// Setiap worker memeriksa key di transaction sendiri, sehingga key baru yang sama di dua
+	// batch bisa di-INSERT dua kali
+	if o.Workers > 1 && o.Mode == ModeUpsert {
+		return fmt.Errorf("parallel workers cannot be used with upsert mode")
+	}
+
+	return nil
+}
+
+// buildUpsertQuery membuat statement upsert tanpa membutuhkan unique constraint pada key.
+// Baris yang key-nya sudah ada di-UPDATE (id dan upsertPreservedColumns tidak disentuh,
+// nilai NULL dari Excel tidak menimpa data lama), sisanya di-INSERT. Query mengembalikan
+// satu baris berisi jumlah baris yang di-update dan di-insert.
+//
+// Setiap tuple harus sudah di-cast ke tipe kolomnya, karena VALUES tidak bisa
+// menebak tipe dari NULL. Jika runID diisi, perubahan dicatat ke import_run_rows.
+func buildUpsertQuery(table string, columns []sqlColumn, key string, tuples []string, runID int64) string {
+	return buildUpsertFromSource(table, columns, key, "VALUES\n\t"+strings.Join(tuples, ",\n\t"), runID)
+}
+
+// buildUpsertFromSource sama seperti buildUpsertQuery, tetapi data baru diambil dari query
+// source (VALUES atau SELECT dari tabel staging) yang kolomnya berurutan sesuai columns.
+//
+// Karena key tidak unik di tabel, baris source dengan key yang sama hanya dipakai yang
+// terakhir (CTE v); tanpa itu keduanya akan di-INSERT, atau UPDATE memilih salah satunya
+// secara acak. Baris tanpa key selalu di-INSERT.
+//
+// Jika runID diisi, id baris yang di-insert dan isi lama baris yang di-update dicatat ke
+// import_run_rows di statement yang sama. CTE old membaca snapshot sebelum UPDATE, sehingga
+// before-image berisi nilai sebelum import.
+func buildUpsertFromSource(table string, columns []sqlColumn, key string, source string, runID int64) string {
+	names := columnNames(columns)
+
+	assignments := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column.Name == key || upsertPreservedColumns[column.Name] {
+			continue
+		}
+		assignments = append(assignments, fmt.Sprintf("%s = COALESCE(v.%s, t.%s)", column.Name, column.Name, column.Name))
+	}
+
+	returning := "1"
+	if runID > 0 {
+		returning = "id"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "WITH src (%s) AS (\n\t%s\n),\n", names, source)
+	sb.WriteString("numbered AS (\n\tSELECT src.*, row_number() OVER () AS import_ord FROM src\n),\n")
+	fmt.Fprintf(&sb, "v AS (\n\t(SELECT DISTINCT ON (%s) %s FROM numbered WHERE %s IS NOT NULL ORDER BY %s, import_ord DESC)\n"+
+		"\tUNION ALL\n\tSELECT %s FROM numbered WHERE %s IS NULL\n),\n", key, names, key, key, names, key)
+	if runID > 0 {
+		fmt.Fprintf(&sb, "old AS (\n\tSELECT t.* FROM %s t WHERE t.%s IN (SELECT %s FROM v)\n),\n", table, key, key)
+	}
+	fmt.Fprintf(&sb, "upd AS (\n\tUPDATE %s t SET\n\t\t%s\n\tFROM v WHERE t.%s = v.%s\n\tRETURNING t.%s",
+		table, strings.Join(assignments, ",\n\t\t"), key, key, key)
+	if runID > 0 {
+		sb.WriteString(", t.id")
+	}
+	sb.WriteString("\n),\n")
+	fmt.Fprintf(&sb, "ins AS (\n\tINSERT INTO %s (%s)\n\tSELECT %s FROM v\n\tWHERE NOT EXISTS (SELECT 1 FROM upd WHERE upd.%s = v.%s)\n\tRETURNING %s\n)",
+		table, names, names, key, key, returning)
+	if runID > 0 {
+		fmt.Fprintf(&sb, ",\ntracked AS (\n\tINSERT INTO import_run_rows (run_id, table_name, row_id, \"action\", before_image)\n"+
+			"\tSELECT %d, '%s', o.id, '%s', to_jsonb(o) FROM old o WHERE o.id IN (SELECT id FROM upd)\n"+
+			"\tUNION ALL\n\tSELECT %d, '%s', id, '%s', NULL FROM ins\n)",
+			runID, table, RunRowUpdate, runID, table, RunRowInsert)
+	}
+	sb.WriteString("\nSELECT (SELECT count(*) FROM upd) AS updated, (SELECT count(*) FROM ins) AS inserted")
+	return sb.String()
+}
+
+// buildTrackedInsert membuat INSERT dari source (VALUES atau SELECT) yang mencatat id setiap
+// baris baru ke import_run_rows lewat RETURNING id. Query mengembalikan jumlah baris yang di-insert.
+func buildTrackedInsert(table string, columns []sqlColumn, source string, runID int64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "WITH ins AS (\n\tINSERT INTO %s (%s) %s\n\tRETURNING id\n),\n", table, columnNames(columns), source)
+	fmt.Fprintf(&sb, "tracked AS (\n\tINSERT INTO import_run_rows (run_id, table_name, row_id, \"action\")\n\tSELECT %d, '%s', id, '%s' FROM ins\n)\n",
+		runID, table, RunRowInsert)
+	sb.WriteString("SELECT count(*) FROM ins")
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildUpsertQueryDeduplicatesKey(t *testing.T) {
+	columns := []sqlColumn{{"barcode", "varchar"}, {"item_name", "varchar"}, {"created_at", "timestamp"}}
+	tuples := []string{"('A'::varchar, 'first'::varchar, NULL::timestamp)", "('A'::varchar, 'second'::varchar, NULL::timestamp)"}
+
+	tests := []struct {
+		name  string
+		runID int64
+		want  []string
+	}{
+		{
+			name: "without run",
+			want: []string{
+				"WITH src (barcode, item_name, created_at) AS (\n\tVALUES",
+				"row_number() OVER () AS import_ord FROM src",
+				"SELECT DISTINCT ON (barcode) barcode, item_name, created_at FROM numbered WHERE barcode IS NOT NULL ORDER BY barcode, import_ord DESC",
+				"SELECT barcode, item_name, created_at FROM numbered WHERE barcode IS NULL",
+				"item_name = COALESCE(v.item_name, t.item_name)",
+				"FROM v WHERE t.barcode = v.barcode",
+				"SELECT barcode, item_name, created_at FROM v\n\tWHERE NOT EXISTS",
+			},
+		},
+		{
+			name:  "with run",
+			runID: 7,
+			want: []string{
+				"SELECT DISTINCT ON (barcode)",
+				"WHERE t.barcode IN (SELECT barcode FROM v)",
+				"SELECT 7, 'm_item', id, 'insert', NULL FROM ins",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := buildUpsertQuery("m_item", columns, "barcode", tuples, tt.runID)
+			for _, part := range tt.want {
+				if !strings.Contains(query, part) {
+					t.Errorf("query does not contain %q:\n%s", part, query)
+				}
+			}
+			if strings.Contains(query, "created_at = ") {
+				t.Errorf("query updates preserved column created_at:\n%s", query)
+			}
+		})
+	}
+}
+
+func TestInsertOptionsValidateWorkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    InsertOptions
+		wantErr bool
+	}{
+		{"sequential upsert", InsertOptions{Mode: ModeUpsert, Workers: 1}, false},
+		{"parallel insert", InsertOptions{Mode: ModeInsert, Workers: 4}, false},
+		{"parallel upsert", InsertOptions{Mode: ModeUpsert, Workers: 4}, true},
+		{"parallel atomic", InsertOptions{Workers: 4, Atomic: true}, true},
+		{"parallel copy", InsertOptions{Workers: 4, Loader: LoaderCopy}, true},
+		{"negative workers", InsertOptions{Workers: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
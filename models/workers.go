@@ -0,0 +1,165 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// ConcurrentImportError import paralel yang berhenti karena satu atau lebih batch gagal.
+// Batch yang sudah commit sebelum pembatalan tetap tersimpan.
+type ConcurrentImportError struct {
+	Batches   int          // Jumlah seluruh batch
+	Committed int          // Batch yang berhasil commit
+	Rows      int          // Jumlah baris pada batch yang berhasil commit
+	Skipped   int          // Batch yang tidak dijalankan atau di-rollback karena import dibatalkan
+	Failed    []BatchError // Batch yang gagal, berurutan sesuai posisi batch
+}
+
+func (e *ConcurrentImportError) Error() string {
+	parts := make([]string, len(e.Failed))
+	for i, failed := range e.Failed {
+		parts[i] = fmt.Sprintf("batch %d-%d: %v", failed.Start, failed.End, failed.Err)
+	}
+	return fmt.Sprintf("%d of %d batches failed, %d committed, %d skipped (%s)",
+		len(e.Failed), e.Batches, e.Committed, e.Skipped, strings.Join(parts, "; "))
+}
+
+// batchJob satu batch yang dikirim ke worker
+type batchJob struct {
+	index      int
+	start, end int // Posisi baris (start 0-based, end eksklusif)
+}
+
+// batchResult hasil satu batch dari worker
+type batchResult struct {
+	batchJob
+	skipped bool
+	err     error
+}
+
+// poolWorkers membatasi jumlah worker agar tidak melebihi MaxOpenConns connection pool
+func poolWorkers(db *sql.DB, workers int) int {
+	if workers <= 1 {
+		return 1
+	}
+	if limit := db.Stats().MaxOpenConnections; limit > 0 && workers > limit {
+		log.Printf("Limiting insert workers from %d to %d (max_open_conn)", workers, limit)
+		return limit
+	}
+	return workers
+}
+
+// insertRowsConcurrent menjalankan batch insert dengan beberapa worker, masing-masing dengan
+// transaction sendiri. Batch pertama yang gagal (atau pembatalan parent) membatalkan context
+// sehingga batch yang sedang berjalan di-rollback dan batch yang belum dimulai dilewati. Hasil
+// batch dicatat ke log berurutan sesuai posisi batch, bukan urutan selesainya.
+func insertRowsConcurrent(parent context.Context, db *sql.DB, t table, rows [][]interface{}, opts InsertOptions, workers int) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	batchSize := t.batchSize()
+	var jobs []batchJob
+	for i := 0; i < len(rows); i += batchSize {
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		jobs = append(jobs, batchJob{index: len(jobs), start: i, end: end})
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	log.Printf("Inserting %d batches with %d workers", len(jobs), workers)
+
+	jobCh := make(chan batchJob)
+	resultCh := make(chan batchResult)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				result := batchResult{batchJob: job}
+				if ctx.Err() != nil {
+					result.skipped = true
+				} else {
+					_, _, result.err = insertBatch(ctx, db, t, rows[job.start:job.end], opts)
+					if result.err != nil {
+						if ctx.Err() != nil {
+							// Batch ini di-rollback karena batch lain gagal lebih dulu
+							result.skipped = true
+						} else {
+							cancel()
+						}
+					}
+				}
+				resultCh <- result
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobCh)
+		for _, job := range jobs {
+			select {
+			case jobCh <- job:
+			case <-ctx.Done():
+				// Batch sisanya tidak dikirim ke worker, tetapi tetap dilaporkan sebagai skipped
+				for _, rest := range jobs[job.index:] {
+					resultCh <- batchResult{batchJob: rest, skipped: true}
+				}
+				return
+			}
+		}
+	}()
+
+	// Kumpulkan hasil dan tulis log berurutan sesuai index batch
+	results := make([]*batchResult, len(jobs))
+	importErr := &ConcurrentImportError{Batches: len(jobs)}
+	next := 0
+	for received := 0; received < len(jobs); received++ {
+		result := <-resultCh
+		results[result.index] = &result
+
+		for ; next < len(jobs) && results[next] != nil; next++ {
+			logBatchResult(*results[next], opts, importErr)
+		}
+	}
+	wg.Wait()
+
+	if parent.Err() != nil {
+		log.Printf("Concurrent import interrupted: %d of %d batches committed", importErr.Committed, importErr.Batches)
+		return &InterruptedError{Committed: importErr.Rows, Err: parent.Err()}
+	}
+	if len(importErr.Failed) > 0 {
+		log.Printf("Concurrent import stopped: %d of %d batches failed, %d committed, %d skipped",
+			len(importErr.Failed), importErr.Batches, importErr.Committed, importErr.Skipped)
+		return importErr
+	}
+	return nil
+}
+
+// logBatchResult mencatat hasil satu batch ke log dan ke ringkasan import
+func logBatchResult(result batchResult, opts InsertOptions, importErr *ConcurrentImportError) {
+	start, end := result.start+1, result.end
+	switch {
+	case result.skipped:
+		importErr.Skipped++
+		log.Printf("Skipped batch %d-%d, import cancelled", start, end)
+	case result.err != nil:
+		importErr.Failed = append(importErr.Failed, BatchError{Start: start, End: end, Err: result.err})
+		log.Printf("Batch %d-%d failed: %v", start, end, result.err)
+	default:
+		importErr.Committed++
+		importErr.Rows += result.end - result.start
+		if opts.OnCommit != nil {
+			opts.OnCommit(result.start, result.end)
+		}
+		log.Printf("Successfully inserted batch %d-%d (%d items)", start, end, end-start+1)
+	}
+}
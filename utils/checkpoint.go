@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Checkpoint posisi terakhir import yang sudah commit, disimpan sebagai file JSON
+// sehingga import yang terhenti bisa dilanjutkan dengan -resume
+type Checkpoint struct {
+	File      string     `json:"file"`
+	SHA256    string     `json:"sha256"`    // Hash workbook, resume ditolak jika file berubah
+	Entity    string     `json:"entity"`    // item atau supplier
+	Mode      string     `json:"mode"`      // insert atau upsert
+	Committed int        `json:"committed"` // Jumlah baris yang sudah commit
+	Rows      []RowRange `json:"rows"`      // Rentang baris Excel per sheet yang sudah commit
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	path string
+}
+
+// RowRange rentang nomor baris Excel (inklusif) pada satu sheet
+type RowRange struct {
+	Sheet string `json:"sheet"`
+	First int    `json:"first"`
+	Last  int    `json:"last"`
+}
+
+// Add mencatat baris Excel first..last pada sheet sebagai sudah commit. Rentang yang
+// bersinggungan pada sheet yang sama digabung, sehingga import berurutan hanya menyimpan
+// satu rentang per sheet.
+func (c *Checkpoint) Add(sheet string, first, last, count int) {
+	c.Committed += count
+	merged := RowRange{Sheet: sheet, First: first, Last: last}
+	kept := make([]RowRange, 0, len(c.Rows)+1)
+	for _, r := range c.Rows {
+		if r.Sheet != sheet || r.Last+1 < merged.First || merged.Last+1 < r.First {
+			kept = append(kept, r)
+			continue
+		}
+		if r.First < merged.First {
+			merged.First = r.First
+		}
+		if r.Last > merged.Last {
+			merged.Last = r.Last
+		}
+	}
+	kept = append(kept, merged)
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Sheet != kept[j].Sheet {
+			return kept[i].Sheet < kept[j].Sheet
+		}
+		return kept[i].First < kept[j].First
+	})
+	c.Rows = kept
+}
+
+// Done melaporkan apakah baris Excel row pada sheet sudah commit pada run sebelumnya
+func (c *Checkpoint) Done(sheet string, row int) bool {
+	for _, r := range c.Rows {
+		if r.Sheet == sheet && row >= r.First && row <= r.Last {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCheckpoint membuat checkpoint kosong yang akan disimpan ke path
+func NewCheckpoint(path string) *Checkpoint {
+	return &Checkpoint{path: path}
+}
+
+// LoadCheckpoint membaca checkpoint dari path. Mengembalikan nil tanpa error jika file tidak ada.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %v", err)
+	}
+
+	cp := &Checkpoint{path: path}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint %s: %v", path, err)
+	}
+	return cp, nil
+}
+
+// Save menulis checkpoint ke file sementara lalu me-rename-nya, sehingga file checkpoint
+// tidak pernah setengah tertulis jika proses mati di tengah penyimpanan
+func (c *Checkpoint) Save() error {
+	c.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+	return nil
+}
+
+// Remove menghapus file checkpoint setelah import selesai
+func (c *Checkpoint) Remove() error {
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing checkpoint: %v", err)
+	}
+	return nil
+}
+
+// Path lokasi file checkpoint
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+// FileSHA256 menghitung hash SHA-256 isi file dalam format hex
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error opening file: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error hashing file: %v", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointAdd(t *testing.T) {
+	type add struct {
+		sheet       string
+		first, last int
+	}
+	tests := []struct {
+		name string
+		adds []add
+		want []RowRange
+	}{
+		{
+			name: "single range",
+			adds: []add{{"Sheet1", 2, 100}},
+			want: []RowRange{{"Sheet1", 2, 100}},
+		},
+		{
+			name: "adjacent ranges merge",
+			adds: []add{{"Sheet1", 2, 100}, {"Sheet1", 101, 200}},
+			want: []RowRange{{"Sheet1", 2, 200}},
+		},
+		{
+			name: "overlapping ranges merge",
+			adds: []add{{"Sheet1", 2, 100}, {"Sheet1", 50, 150}},
+			want: []RowRange{{"Sheet1", 2, 150}},
+		},
+		{
+			name: "contained range",
+			adds: []add{{"Sheet1", 2, 100}, {"Sheet1", 10, 20}},
+			want: []RowRange{{"Sheet1", 2, 100}},
+		},
+		{
+			name: "gap keeps ranges apart",
+			adds: []add{{"Sheet1", 2, 100}, {"Sheet1", 102, 200}},
+			want: []RowRange{{"Sheet1", 2, 100}, {"Sheet1", 102, 200}},
+		},
+		{
+			name: "out of order ranges are sorted",
+			adds: []add{{"Sheet1", 300, 400}, {"Sheet1", 2, 100}},
+			want: []RowRange{{"Sheet1", 2, 100}, {"Sheet1", 300, 400}},
+		},
+		{
+			name: "filling a gap merges both sides",
+			adds: []add{{"Sheet1", 2, 100}, {"Sheet1", 201, 300}, {"Sheet1", 101, 200}},
+			want: []RowRange{{"Sheet1", 2, 300}},
+		},
+		{
+			name: "parallel batches finishing out of order",
+			adds: []add{{"Sheet1", 201, 300}, {"Sheet1", 2, 100}, {"Sheet1", 301, 400}, {"Sheet1", 101, 200}},
+			want: []RowRange{{"Sheet1", 2, 400}},
+		},
+		{
+			name: "sheets are not merged",
+			adds: []add{{"Minuman", 2, 100}, {"Makanan", 101, 200}, {"Makanan", 2, 100}},
+			want: []RowRange{{"Makanan", 2, 200}, {"Minuman", 2, 100}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := NewCheckpoint("")
+			count := 0
+			for _, a := range tt.adds {
+				cp.Add(a.sheet, a.first, a.last, a.last-a.first+1)
+				count += a.last - a.first + 1
+			}
+			if !reflect.DeepEqual(cp.Rows, tt.want) {
+				t.Errorf("Rows = %+v, want %+v", cp.Rows, tt.want)
+			}
+			if cp.Committed != count {
+				t.Errorf("Committed = %d, want %d", cp.Committed, count)
+			}
+		})
+	}
+}
+
+func TestCheckpointDone(t *testing.T) {
+	cp := NewCheckpoint("")
+	cp.Add("Sheet1", 2, 100, 99)
+	cp.Add("Sheet1", 201, 300, 100)
+	cp.Add("Sheet2", 5, 10, 6)
+
+	tests := []struct {
+		sheet string
+		row   int
+		want  bool
+	}{
+		{"Sheet1", 1, false},
+		{"Sheet1", 2, true},
+		{"Sheet1", 100, true},
+		{"Sheet1", 101, false},
+		{"Sheet1", 250, true},
+		{"Sheet1", 301, false},
+		{"Sheet2", 2, false},
+		{"Sheet2", 7, true},
+		{"Sheet3", 7, false},
+	}
+	for _, tt := range tests {
+		if got := cp.Done(tt.sheet, tt.row); got != tt.want {
+			t.Errorf("Done(%s, %d) = %v, want %v", tt.sheet, tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint.json")
+
+	missing, err := LoadCheckpoint(path)
+	if err != nil || missing != nil {
+		t.Fatalf("LoadCheckpoint(missing) = %v, %v, want nil, nil", missing, err)
+	}
+
+	cp := NewCheckpoint(path)
+	cp.File, cp.SHA256, cp.Entity, cp.Mode = "MasterBarang.xlsx", "abc123", "item", "upsert"
+	cp.Add("Sheet1", 2, 1000, 999)
+	if err := cp.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if loaded.File != cp.File || loaded.SHA256 != cp.SHA256 || loaded.Entity != cp.Entity || loaded.Mode != cp.Mode ||
+		loaded.Committed != cp.Committed || !reflect.DeepEqual(loaded.Rows, cp.Rows) || loaded.Path() != path {
+		t.Errorf("loaded checkpoint = %+v, want %+v", loaded, cp)
+	}
+
+	if err := loaded.Remove(); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := loaded.Remove(); err != nil {
+		t.Errorf("Remove of a removed checkpoint: %v", err)
+	}
+}